@@ -69,9 +69,10 @@ func (es *ExpressionStatement) TokenLiteral() string {
 }
 
 type LetStatement struct {
-	Token token.Token
-	Name  *Identifier
-	Value Expression
+	Token    token.Token
+	Name     *Identifier
+	Value    Expression
+	Constant bool
 }
 
 func (ls *LetStatement) statementNode() {}
@@ -145,6 +146,19 @@ func (il *IntegerLiteral) TokenLiteral() string {
 	return il.Token.Literal
 }
 
+type FloatLiteral struct {
+	Token token.Token
+	Value float64
+}
+
+func (fl *FloatLiteral) expressionNode() {}
+func (fl *FloatLiteral) String() string {
+	return fl.Token.Literal
+}
+func (fl *FloatLiteral) TokenLiteral() string {
+	return fl.Token.Literal
+}
+
 type StringLiteral struct {
 	Token token.Token
 	Value string
@@ -182,6 +196,31 @@ func (a *Array) String() string {
 	return result.String()
 }
 
+type HashLiteral struct {
+	Token  token.Token
+	Keys   []Expression
+	Values []Expression
+}
+
+func (hl *HashLiteral) expressionNode() {}
+func (hl *HashLiteral) TokenLiteral() string {
+	return hl.Token.Literal
+}
+func (hl *HashLiteral) String() string {
+	var result bytes.Buffer
+
+	pairs := []string{}
+	for i, key := range hl.Keys {
+		pairs = append(pairs, key.String()+": "+hl.Values[i].String())
+	}
+
+	result.WriteString("{")
+	result.WriteString(strings.Join(pairs, ", "))
+	result.WriteString("}")
+
+	return result.String()
+}
+
 type PrefixExpression struct {
 	Token    token.Token
 	Operator string
@@ -237,6 +276,18 @@ func (b *Boolean) String() string {
 	return b.Token.Literal
 }
 
+type Null struct {
+	Token token.Token
+}
+
+func (n *Null) expressionNode() {}
+func (n *Null) TokenLiteral() string {
+	return n.Token.Literal
+}
+func (n *Null) String() string {
+	return n.Token.Literal
+}
+
 type BlockStatement struct {
 	Token      token.Token
 	Statements []Statement
@@ -286,6 +337,8 @@ type FunctionLiteral struct {
 	Token      token.Token
 	Name       Identifier
 	Parameters []Identifier
+	Defaults   map[string]Expression
+	Rest       *Identifier
 	Block      BlockStatement
 }
 
@@ -301,9 +354,7 @@ func (fl *FunctionLiteral) String() string {
 		result.WriteString(fl.Name.Value)
 	}
 	result.WriteString(" (")
-	for _, s := range fl.Parameters {
-		result.WriteString(s.String() + ", ")
-	}
+	result.WriteString(strings.Join(ParametersString(fl.Parameters, fl.Defaults, fl.Rest), ", "))
 	result.WriteString(") ")
 
 	result.WriteString(fl.Block.String())
@@ -311,11 +362,30 @@ func (fl *FunctionLiteral) String() string {
 	return result.String()
 }
 
+// ParametersString renders a parameter list, including default values and
+// the rest parameter, the same way it is written in the source code.
+func ParametersString(parameters []Identifier, defaults map[string]Expression, rest *Identifier) []string {
+	result := []string{}
+
+	for _, p := range parameters {
+		if value, ok := defaults[p.Value]; ok {
+			result = append(result, p.String()+" = "+value.String())
+		} else {
+			result = append(result, p.String())
+		}
+	}
+
+	if rest != nil {
+		result = append(result, "..."+rest.String())
+	}
+
+	return result
+}
+
 type CallExpression struct {
 	Token     token.Token
 	Function  Expression
 	Arguments []Expression
-	Lib       string
 }
 
 func (ce *CallExpression) expressionNode() {}
@@ -338,10 +408,38 @@ func (ce *CallExpression) String() string {
 	return result.String()
 }
 
+type SpreadExpression struct {
+	Token token.Token
+	Value Expression
+}
+
+func (se *SpreadExpression) expressionNode() {}
+func (se *SpreadExpression) TokenLiteral() string {
+	return se.Token.Literal
+}
+func (se *SpreadExpression) String() string {
+	return "..." + se.Value.String()
+}
+
+type NamedArgument struct {
+	Token token.Token
+	Name  Identifier
+	Value Expression
+}
+
+func (na *NamedArgument) expressionNode() {}
+func (na *NamedArgument) TokenLiteral() string {
+	return na.Token.Literal
+}
+func (na *NamedArgument) String() string {
+	return na.Name.String() + " = " + na.Value.String()
+}
+
 type ArrayAccessExpression struct {
 	Token    token.Token
 	Array    Expression
 	Position Expression
+	Optional bool
 }
 
 func (aae *ArrayAccessExpression) expressionNode() {}
@@ -352,13 +450,42 @@ func (aae *ArrayAccessExpression) String() string {
 	var result bytes.Buffer
 
 	result.WriteString(aae.Array.String())
-	result.WriteString("[")
+	result.WriteString(aae.TokenLiteral())
 	result.WriteString(aae.Position.String())
 	result.WriteString("]")
 
 	return result.String()
 }
 
+type SliceExpression struct {
+	Token    token.Token
+	Left     Expression
+	Start    Expression
+	End      Expression
+	Optional bool
+}
+
+func (se *SliceExpression) expressionNode() {}
+func (se *SliceExpression) TokenLiteral() string {
+	return se.Token.Literal
+}
+func (se *SliceExpression) String() string {
+	var result bytes.Buffer
+
+	result.WriteString(se.Left.String())
+	result.WriteString(se.TokenLiteral())
+	if se.Start != nil {
+		result.WriteString(se.Start.String())
+	}
+	result.WriteString(":")
+	if se.End != nil {
+		result.WriteString(se.End.String())
+	}
+	result.WriteString("]")
+
+	return result.String()
+}
+
 type ReassignmentStatement struct {
 	Token    token.Token
 	Variable Identifier
@@ -382,6 +509,8 @@ func (rs *ReassignmentStatement) String() string {
 type UseStatement struct {
 	Token    token.Token
 	Filename string
+	Alias    string
+	Names    []Identifier
 }
 
 func (us *UseStatement) statementNode() {}
@@ -389,13 +518,43 @@ func (us *UseStatement) TokenLiteral() string {
 	return us.Token.Literal
 }
 func (us *UseStatement) String() string {
-	return "use " + us.Filename
+	var result bytes.Buffer
+
+	result.WriteString("use " + us.Filename)
+
+	if us.Alias != "" {
+		result.WriteString(" as " + us.Alias)
+	}
+
+	if us.Names != nil {
+		names := []string{}
+		for _, n := range us.Names {
+			names = append(names, n.String())
+		}
+		result.WriteString(" { " + strings.Join(names, ", ") + " }")
+	}
+
+	return result.String()
+}
+
+type ExportStatement struct {
+	Token     token.Token
+	Statement Statement
+}
+
+func (es *ExportStatement) statementNode() {}
+func (es *ExportStatement) TokenLiteral() string {
+	return es.Token.Literal
+}
+func (es *ExportStatement) String() string {
+	return "export " + es.Statement.String()
 }
 
 type ExternalReferenceExpression struct {
 	Token    token.Token
-	Module   string
+	Left     Expression
 	Referece Expression
+	Optional bool
 }
 
 func (ere *ExternalReferenceExpression) expressionNode() {}
@@ -405,8 +564,8 @@ func (ere *ExternalReferenceExpression) TokenLiteral() string {
 func (ere *ExternalReferenceExpression) String() string {
 	var result bytes.Buffer
 
-	result.WriteString(ere.Module)
-	result.WriteString(".")
+	result.WriteString(ere.Left.String())
+	result.WriteString(ere.TokenLiteral())
 	result.WriteString(ere.Referece.String())
 
 	return result.String()
@@ -0,0 +1,323 @@
+package evaluator
+
+import (
+	"fmt"
+	"monkey/object"
+)
+
+// push, pop, shift and unshift modify the array they are given, and fail on
+// frozen arrays. Every other array builtin leaves its arguments untouched and
+// returns a new array.
+
+func checkMutable(name string, arr *object.Array) *object.Error {
+	if arr.Frozen {
+		return newError(name + ": cannot modify a frozen array")
+	}
+	return nil
+}
+
+// b_push appends the values to the end of the array and returns the array.
+func b_push(args ...object.Object) object.Object {
+	if len(args) < 2 {
+		return newError(fmt.Sprintf("push: invalid number of arguments, want at least 2, got %d", len(args)))
+	}
+
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return newError("push: argument 1 must be ARRAY, got " + args[0].Type())
+	}
+
+	if err := checkMutable("push", arr); err != nil {
+		return err
+	}
+
+	arr.Elements = append(arr.Elements, args[1:]...)
+
+	return arr
+}
+
+// b_pop removes the last element of the array and returns it, or null if the
+// array is empty.
+func b_pop(args ...object.Object) object.Object {
+	if err := checkArgs("pop", args, object.ARRAY_OBJ); err != nil {
+		return err
+	}
+
+	arr := args[0].(*object.Array)
+	if err := checkMutable("pop", arr); err != nil {
+		return err
+	}
+
+	if len(arr.Elements) == 0 {
+		return NULL
+	}
+
+	last := arr.Elements[len(arr.Elements)-1]
+	arr.Elements = arr.Elements[:len(arr.Elements)-1]
+
+	return last
+}
+
+// b_shift removes the first element of the array and returns it, or null if
+// the array is empty.
+func b_shift(args ...object.Object) object.Object {
+	if err := checkArgs("shift", args, object.ARRAY_OBJ); err != nil {
+		return err
+	}
+
+	arr := args[0].(*object.Array)
+	if err := checkMutable("shift", arr); err != nil {
+		return err
+	}
+
+	if len(arr.Elements) == 0 {
+		return NULL
+	}
+
+	first := arr.Elements[0]
+	arr.Elements = append([]object.Object{}, arr.Elements[1:]...)
+
+	return first
+}
+
+// b_unshift inserts the values at the start of the array and returns the
+// array.
+func b_unshift(args ...object.Object) object.Object {
+	if len(args) < 2 {
+		return newError(fmt.Sprintf("unshift: invalid number of arguments, want at least 2, got %d", len(args)))
+	}
+
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return newError("unshift: argument 1 must be ARRAY, got " + args[0].Type())
+	}
+
+	if err := checkMutable("unshift", arr); err != nil {
+		return err
+	}
+
+	arr.Elements = append(append([]object.Object{}, args[1:]...), arr.Elements...)
+
+	return arr
+}
+
+func b_first(args ...object.Object) object.Object {
+	if err := checkArgs("first", args, object.ARRAY_OBJ); err != nil {
+		return err
+	}
+
+	elements := args[0].(*object.Array).Elements
+	if len(elements) == 0 {
+		return NULL
+	}
+
+	return elements[0]
+}
+
+func b_last(args ...object.Object) object.Object {
+	if err := checkArgs("last", args, object.ARRAY_OBJ); err != nil {
+		return err
+	}
+
+	elements := args[0].(*object.Array).Elements
+	if len(elements) == 0 {
+		return NULL
+	}
+
+	return elements[len(elements)-1]
+}
+
+// b_rest returns every element but the first one.
+func b_rest(args ...object.Object) object.Object {
+	if err := checkArgs("rest", args, object.ARRAY_OBJ); err != nil {
+		return err
+	}
+
+	elements := args[0].(*object.Array).Elements
+	if len(elements) == 0 {
+		return &object.Array{Elements: []object.Object{}}
+	}
+
+	return &object.Array{Elements: append([]object.Object{}, elements[1:]...)}
+}
+
+func b_concat(args ...object.Object) object.Object {
+	result := []object.Object{}
+
+	for i, arg := range args {
+		arr, ok := arg.(*object.Array)
+		if !ok {
+			return newError(fmt.Sprintf("concat: argument %d must be ARRAY, got %s", i+1, arg.Type()))
+		}
+		result = append(result, arr.Elements...)
+	}
+
+	return &object.Array{Elements: result}
+}
+
+func b_reverse(args ...object.Object) object.Object {
+	if err := checkArgs("reverse", args, object.ARRAY_OBJ); err != nil {
+		return err
+	}
+
+	elements := args[0].(*object.Array).Elements
+	result := make([]object.Object, len(elements))
+	for i, element := range elements {
+		result[len(elements)-1-i] = element
+	}
+
+	return &object.Array{Elements: result}
+}
+
+func b_contains(args ...object.Object) object.Object {
+	if err := checkArgs("contains", args, object.ARRAY_OBJ, ""); err != nil {
+		return err
+	}
+
+	return nativeBoolToBooleanObject(indexOf(args[0].(*object.Array), args[1]) >= 0)
+}
+
+// b_index_of returns the position of the first element equal to the value,
+// or -1 if there is none.
+func b_index_of(args ...object.Object) object.Object {
+	if err := checkArgs("index_of", args, object.ARRAY_OBJ, ""); err != nil {
+		return err
+	}
+
+	return &object.Integer{Value: int64(indexOf(args[0].(*object.Array), args[1]))}
+}
+
+func indexOf(arr *object.Array, value object.Object) int {
+	for i, element := range arr.Elements {
+		if object.Equal(element, value) {
+			return i
+		}
+	}
+	return -1
+}
+
+// b_flatten inlines nested arrays, all the way down or only depth levels.
+func b_flatten(args ...object.Object) object.Object {
+	if err := checkOptionalArgs("flatten", args, 1, object.ARRAY_OBJ, object.INTEGER_OBJ); err != nil {
+		return err
+	}
+
+	depth := int64(-1)
+	if len(args) == 2 {
+		depth = args[1].(*object.Integer).Value
+	}
+
+	arr := args[0].(*object.Array)
+
+	elements, err := flatten(arr.Elements, depth, map[*object.Array]bool{arr: true})
+	if err != nil {
+		return err
+	}
+
+	return &object.Array{Elements: elements}
+}
+
+// flatten keeps track of the arrays it is inside of and rejects an array that
+// contains itself, whatever the depth, since each level is a recursive call.
+func flatten(elements []object.Object, depth int64, visiting map[*object.Array]bool) ([]object.Object, *object.Error) {
+	result := []object.Object{}
+
+	for _, element := range elements {
+		arr, ok := element.(*object.Array)
+		if !ok || depth == 0 {
+			result = append(result, element)
+			continue
+		}
+
+		if visiting[arr] {
+			return nil, newError("flatten: cannot flatten an array that contains itself")
+		}
+
+		visiting[arr] = true
+		flattened, err := flatten(arr.Elements, depth-1, visiting)
+		delete(visiting, arr)
+
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, flattened...)
+	}
+
+	return result, nil
+}
+
+// b_zip pairs up the elements of the arrays in the same position, stopping
+// at the end of the shortest one.
+func b_zip(args ...object.Object) object.Object {
+	if len(args) == 0 {
+		return newError("zip: want at least one array")
+	}
+
+	length := -1
+	arrays := make([]*object.Array, len(args))
+
+	for i, arg := range args {
+		arr, ok := arg.(*object.Array)
+		if !ok {
+			return newError(fmt.Sprintf("zip: argument %d must be ARRAY, got %s", i+1, arg.Type()))
+		}
+		arrays[i] = arr
+
+		if length < 0 || len(arr.Elements) < length {
+			length = len(arr.Elements)
+		}
+	}
+
+	result := make([]object.Object, length)
+	for i := range result {
+		tuple := make([]object.Object, len(arrays))
+		for j, arr := range arrays {
+			tuple[j] = arr.Elements[i]
+		}
+		result[i] = &object.Array{Elements: tuple}
+	}
+
+	return &object.Array{Elements: result}
+}
+
+// b_unique keeps the first occurrence of every value.
+func b_unique(args ...object.Object) object.Object {
+	if err := checkArgs("unique", args, object.ARRAY_OBJ); err != nil {
+		return err
+	}
+
+	result := &object.Array{Elements: []object.Object{}}
+	for _, element := range args[0].(*object.Array).Elements {
+		if indexOf(result, element) < 0 {
+			result.Elements = append(result.Elements, element)
+		}
+	}
+
+	return result
+}
+
+// b_chunk splits the array in arrays of size elements, the last one holding
+// whatever is left.
+func b_chunk(args ...object.Object) object.Object {
+	if err := checkArgs("chunk", args, object.ARRAY_OBJ, object.INTEGER_OBJ); err != nil {
+		return err
+	}
+
+	elements := args[0].(*object.Array).Elements
+	size := int(args[1].(*object.Integer).Value)
+
+	if size <= 0 {
+		return newError("chunk: the size must be positive")
+	}
+
+	result := []object.Object{}
+	for start := 0; start < len(elements); start += size {
+		end := start + size
+		if end > len(elements) {
+			end = len(elements)
+		}
+		result = append(result, &object.Array{Elements: append([]object.Object{}, elements[start:end]...)})
+	}
+
+	return &object.Array{Elements: result}
+}
@@ -1,11 +1,12 @@
 package evaluator
 
 import (
-	"bufio"
 	"fmt"
 	"monkey/object"
-	"os"
+	"sort"
 	"strconv"
+	"strings"
+	"unicode/utf8"
 )
 
 func b_puts(args ...object.Object) object.Object {
@@ -15,20 +16,13 @@ func b_puts(args ...object.Object) object.Object {
 	}
 
 	for _, arg := range args {
-		switch arg := arg.(type) {
-		case *object.String:
-			fmt.Println(arg.Value)
-		case *object.Integer:
-			fmt.Println(arg.Value)
-		case *object.Boolean:
-			fmt.Println(arg.Value)
-		}
+		fmt.Println(arg.Inspect())
 	}
 
 	return NULL
 }
 
-func b_read(args ...object.Object) object.Object {
+func b_read(interp object.Interpreter, args ...object.Object) object.Object {
 
 	if len(args) != 1 {
 		return newError(fmt.Sprintf("Invalid number of arguments, want 1, got %d", len(args)))
@@ -36,15 +30,15 @@ func b_read(args ...object.Object) object.Object {
 
 	b_puts(args...)
 
-	scanner := bufio.NewScanner(os.Stdin)
-	scanner.Scan()
-
-	err := scanner.Err()
-	if err != nil {
-		return newError("could not read the user input, " + err.Error())
+	stdin := interp.(*Interpreter).Stdin
+	if !stdin.Scan() {
+		if err := stdin.Err(); err != nil {
+			return newError("could not read the user input, " + err.Error())
+		}
+		return &object.String{Value: ""}
 	}
 
-	return &object.String{Value: scanner.Text()}
+	return &object.String{Value: stdin.Text()}
 }
 
 func b_len(args ...object.Object) object.Object {
@@ -54,11 +48,15 @@ func b_len(args ...object.Object) object.Object {
 
 	switch arg := args[0].(type) {
 	case *object.String:
+		return &object.Integer{Value: int64(utf8.RuneCountInString(arg.Value))}
+	case *object.Bytes:
 		return &object.Integer{Value: int64(len(arg.Value))}
 	case *object.Array:
 		return &object.Integer{Value: int64(len(arg.Elements))}
+	case *object.Hash:
+		return &object.Integer{Value: int64(len(arg.Pairs))}
 	default:
-		return newError("len only supports string and array arguments")
+		return newError("len only supports string, bytes, array and hash arguments")
 	}
 }
 
@@ -76,11 +74,14 @@ func b_int(args ...object.Object) object.Object {
 		return &object.Integer{Value: value}
 	case *object.Integer:
 		return arg
+	case *object.Float:
+		return &object.Integer{Value: int64(arg.Value)}
 	default:
 		return newError("Invalid argument, want a string, got " + arg.Type())
 	}
 }
 
+// b_str converts any value to a string, the way puts would print it.
 func b_str(args ...object.Object) object.Object {
 
 	if len(args) != 1 {
@@ -88,29 +89,572 @@ func b_str(args ...object.Object) object.Object {
 	}
 
 	switch arg := args[0].(type) {
-	case *object.Integer:
-		value := strconv.FormatInt(arg.Value, 10)
-		return &object.String{Value: value}
+	case *object.String:
+		return arg
+	case *object.Bytes:
+		return &object.String{Value: string(arg.Value)}
+	}
+
+	return &object.String{Value: args[0].Inspect()}
+}
+
+// b_bytes returns the UTF-8 encoding of a string, or builds bytes from an
+// array of integers between 0 and 255.
+func b_bytes(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError(fmt.Sprintf("Invalid number of arguments, want 1, got %d", len(args)))
+	}
+
+	switch arg := args[0].(type) {
+	case *object.String:
+		return &object.Bytes{Value: []byte(arg.Value)}
+	case *object.Bytes:
+		return &object.Bytes{Value: append([]byte{}, arg.Value...)}
+	case *object.Array:
+		value := make([]byte, len(arg.Elements))
+		for i, element := range arg.Elements {
+			integer, ok := element.(*object.Integer)
+			if !ok || integer.Value < 0 || integer.Value > 255 {
+				return newError(fmt.Sprintf("bytes: element %d must be an INTEGER between 0 and 255, got %s", i, element.Inspect()))
+			}
+			value[i] = byte(integer.Value)
+		}
+		return &object.Bytes{Value: value}
 	default:
-		return newError("argument type not supported")
-	}
-
-}
-
-var builtins = map[string]*object.Builtin{
-	"len": &object.Builtin{
-		Fn: b_len,
-	},
-	"puts": &object.Builtin{
-		Fn: b_puts,
-	},
-	"read": &object.Builtin{
-		Fn: b_read,
-	},
-	"int": &object.Builtin{
-		Fn: b_int,
-	},
-	"str": &object.Builtin{
-		Fn: b_str,
-	},
+		return newError("bytes: argument must be a STRING, BYTES or ARRAY, got " + arg.Type())
+	}
+}
+
+// b_type returns the name of the type of a value, such as "integer" or
+// "hash".
+func b_type(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError(fmt.Sprintf("Invalid number of arguments, want 1, got %d", len(args)))
+	}
+
+	return &object.String{Value: strings.ToLower(args[0].Type())}
+}
+
+// b_bool follows the same rules as if and while: false and null are false,
+// every other value, including 0, "" and [], is true.
+func b_bool(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError(fmt.Sprintf("Invalid number of arguments, want 1, got %d", len(args)))
+	}
+
+	return nativeBoolToBooleanObject(isTruthy(args[0]))
+}
+
+func typePredicate(types ...string) object.BuiltinFunction {
+	return func(args ...object.Object) object.Object {
+		if len(args) != 1 {
+			return newError(fmt.Sprintf("Invalid number of arguments, want 1, got %d", len(args)))
+		}
+
+		for _, t := range types {
+			if args[0].Type() == t {
+				return TRUE
+			}
+		}
+
+		return FALSE
+	}
+}
+
+// b_freeze makes an array or a hash, and everything nested in it, immutable.
+// Builtins that modify their arguments in place refuse to touch frozen values.
+func b_freeze(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return newError(fmt.Sprintf("Invalid number of arguments, want 1, got %d", len(args)))
+	}
+
+	freeze(args[0])
+
+	return args[0]
+}
+
+func freeze(obj object.Object) {
+	switch obj := obj.(type) {
+	case *object.Array:
+		if !obj.Frozen {
+			obj.Frozen = true
+			for _, element := range obj.Elements {
+				freeze(element)
+			}
+		}
+	case *object.Hash:
+		if !obj.Frozen {
+			obj.Frozen = true
+			for _, pair := range obj.Pairs {
+				freeze(pair.Value)
+			}
+		}
+	}
+}
+
+func b_map(interp object.Interpreter, args ...object.Object) object.Object {
+	arr, fn, err := arrayAndFunctionArgs("map", args)
+	if err != nil {
+		return err
+	}
+
+	result := make([]object.Object, 0, len(arr.Elements))
+	for _, element := range arr.Elements {
+		value := interp.Call(fn, element)
+		if isError(value) {
+			return value
+		}
+		result = append(result, value)
+	}
+
+	return &object.Array{Elements: result}
+}
+
+func b_filter(interp object.Interpreter, args ...object.Object) object.Object {
+	arr, fn, err := arrayAndFunctionArgs("filter", args)
+	if err != nil {
+		return err
+	}
+
+	result := []object.Object{}
+	for _, element := range arr.Elements {
+		keep := interp.Call(fn, element)
+		if isError(keep) {
+			return keep
+		}
+		if isTruthy(keep) {
+			result = append(result, element)
+		}
+	}
+
+	return &object.Array{Elements: result}
+}
+
+func b_reduce(interp object.Interpreter, args ...object.Object) object.Object {
+	if len(args) != 2 && len(args) != 3 {
+		return newError(fmt.Sprintf("Invalid number of arguments, want 2 or 3, got %d", len(args)))
+	}
+
+	arr, fn, err := arrayAndFunctionArgs("reduce", args[:2])
+	if err != nil {
+		return err
+	}
+
+	elements := arr.Elements
+	var accumulator object.Object
+
+	if len(args) == 3 {
+		accumulator = args[2]
+	} else {
+		if len(elements) == 0 {
+			return newError("reduce of an empty array with no initial value")
+		}
+		accumulator = elements[0]
+		elements = elements[1:]
+	}
+
+	for _, element := range elements {
+		accumulator = interp.Call(fn, accumulator, element)
+		if isError(accumulator) {
+			return accumulator
+		}
+	}
+
+	return accumulator
+}
+
+func b_each(interp object.Interpreter, args ...object.Object) object.Object {
+	arr, fn, err := arrayAndFunctionArgs("each", args)
+	if err != nil {
+		return err
+	}
+
+	for _, element := range arr.Elements {
+		result := interp.Call(fn, element)
+		if isError(result) {
+			return result
+		}
+	}
+
+	return NULL
+}
+
+func b_any(interp object.Interpreter, args ...object.Object) object.Object {
+	arr, fn, err := arrayAndFunctionArgs("any", args)
+	if err != nil {
+		return err
+	}
+
+	for _, element := range arr.Elements {
+		result := interp.Call(fn, element)
+		if isError(result) {
+			return result
+		}
+		if isTruthy(result) {
+			return TRUE
+		}
+	}
+
+	return FALSE
+}
+
+func b_all(interp object.Interpreter, args ...object.Object) object.Object {
+	arr, fn, err := arrayAndFunctionArgs("all", args)
+	if err != nil {
+		return err
+	}
+
+	for _, element := range arr.Elements {
+		result := interp.Call(fn, element)
+		if isError(result) {
+			return result
+		}
+		if !isTruthy(result) {
+			return FALSE
+		}
+	}
+
+	return TRUE
+}
+
+func b_find(interp object.Interpreter, args ...object.Object) object.Object {
+	arr, fn, err := arrayAndFunctionArgs("find", args)
+	if err != nil {
+		return err
+	}
+
+	for _, element := range arr.Elements {
+		result := interp.Call(fn, element)
+		if isError(result) {
+			return result
+		}
+		if isTruthy(result) {
+			return element
+		}
+	}
+
+	return NULL
+}
+
+// b_sort returns a sorted copy of the array. Without a comparator only
+// arrays of integers or of strings can be sorted; the comparator may return
+// either a boolean (a goes before b) or an integer (negative, zero, positive).
+func b_sort(interp object.Interpreter, args ...object.Object) object.Object {
+	if len(args) != 1 && len(args) != 2 {
+		return newError(fmt.Sprintf("Invalid number of arguments, want 1 or 2, got %d", len(args)))
+	}
+
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return newError("sort expects an array as its first argument, got " + args[0].Type())
+	}
+
+	elements := append([]object.Object{}, arr.Elements...)
+
+	var less func(a, b object.Object) (bool, *object.Error)
+
+	if len(args) == 2 {
+		fn := args[1]
+		if fn.Type() != object.FUNCTION_OBJ && fn.Type() != object.BUILTIN_OBJ {
+			return newError("sort expects a function as its second argument, got " + fn.Type())
+		}
+
+		less = func(a, b object.Object) (bool, *object.Error) {
+			result := interp.Call(fn, a, b)
+			switch result := result.(type) {
+			case *object.Error:
+				return false, result
+			case *object.Boolean:
+				return result.Value, nil
+			case *object.Integer:
+				return result.Value < 0, nil
+			default:
+				return false, newError("sort comparator must return a boolean or an integer, got " + result.Type())
+			}
+		}
+	} else {
+		less = compareObjects
+	}
+
+	if err := sortObjects(elements, elements, less); err != nil {
+		return err
+	}
+
+	return &object.Array{Elements: elements}
+}
+
+func b_sort_by(interp object.Interpreter, args ...object.Object) object.Object {
+	arr, fn, err := arrayAndFunctionArgs("sort_by", args)
+	if err != nil {
+		return err
+	}
+
+	elements := append([]object.Object{}, arr.Elements...)
+	keys := make([]object.Object, len(elements))
+
+	for i, element := range elements {
+		keys[i] = interp.Call(fn, element)
+		if isError(keys[i]) {
+			return keys[i]
+		}
+	}
+
+	if err := sortObjects(elements, keys, compareObjects); err != nil {
+		return err
+	}
+
+	return &object.Array{Elements: elements}
+}
+
+// sortObjects stable sorts elements by the matching entries in keys, stopping
+// at the first error returned by less.
+func sortObjects(elements, keys []object.Object, less func(a, b object.Object) (bool, *object.Error)) *object.Error {
+	var sortErr *object.Error
+
+	indexes := make([]int, len(elements))
+	for i := range indexes {
+		indexes[i] = i
+	}
+
+	sort.SliceStable(indexes, func(i, j int) bool {
+		if sortErr != nil {
+			return false
+		}
+		result, err := less(keys[indexes[i]], keys[indexes[j]])
+		if err != nil {
+			sortErr = err
+		}
+		return result
+	})
+
+	if sortErr != nil {
+		return sortErr
+	}
+
+	sorted := make([]object.Object, len(elements))
+	for i, index := range indexes {
+		sorted[i] = elements[index]
+	}
+	copy(elements, sorted)
+
+	return nil
+}
+
+func compareObjects(a, b object.Object) (bool, *object.Error) {
+	switch a := a.(type) {
+	case *object.Integer:
+		if b, ok := b.(*object.Integer); ok {
+			return a.Value < b.Value, nil
+		}
+	case *object.String:
+		if b, ok := b.(*object.String); ok {
+			return a.Value < b.Value, nil
+		}
+	}
+
+	if isNumber(a) && isNumber(b) {
+		return toFloat(a) < toFloat(b), nil
+	}
+
+	return false, newError("cannot compare " + a.Type() + " with " + b.Type() + ", pass a comparator function")
+}
+
+func newStringHash(pairs map[string]object.Object) *object.Hash {
+	hash := object.NewHash()
+	for k, v := range pairs {
+		key := &object.String{Value: k}
+		hash.Pairs[key.HashKey()] = object.HashPair{Key: key, Value: v}
+	}
+	return hash
+}
+
+// checkArgs makes sure a builtin got exactly one argument for each of the
+// given types. An empty type accepts any object.
+func checkArgs(name string, args []object.Object, types ...string) *object.Error {
+	return checkOptionalArgs(name, args, len(types), types...)
+}
+
+// checkOptionalArgs is like checkArgs, but only the first required arguments
+// must be present.
+func checkOptionalArgs(name string, args []object.Object, required int, types ...string) *object.Error {
+	if len(args) < required || len(args) > len(types) {
+		if required == len(types) {
+			return newError(fmt.Sprintf("%s: invalid number of arguments, want %d, got %d", name, required, len(args)))
+		}
+		return newError(fmt.Sprintf("%s: invalid number of arguments, want %d to %d, got %d", name, required, len(types), len(args)))
+	}
+
+	for i, arg := range args {
+		if types[i] != "" && arg.Type() != types[i] {
+			return newError(fmt.Sprintf("%s: argument %d must be %s, got %s", name, i+1, types[i], arg.Type()))
+		}
+	}
+
+	return nil
+}
+
+func arrayAndFunctionArgs(name string, args []object.Object) (*object.Array, object.Object, *object.Error) {
+	if len(args) != 2 {
+		return nil, nil, newError(fmt.Sprintf("Invalid number of arguments, want 2, got %d", len(args)))
+	}
+
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return nil, nil, newError(name + " expects an array as its first argument, got " + args[0].Type())
+	}
+
+	if args[1].Type() != object.FUNCTION_OBJ && args[1].Type() != object.BUILTIN_OBJ {
+		return nil, nil, newError(name + " expects a function as its second argument, got " + args[1].Type())
+	}
+
+	return arr, args[1], nil
+}
+
+// builtins is filled in init, like the native modules are.
+var builtins map[string]*object.Builtin
+
+func init() {
+	builtins = map[string]*object.Builtin{
+		"len": &object.Builtin{
+			Fn: b_len,
+		},
+		"puts": &object.Builtin{
+			Fn: b_puts,
+		},
+		"print": &object.Builtin{
+			Fn: b_print,
+		},
+		"println": &object.Builtin{
+			Fn: b_println,
+		},
+		"printf": &object.Builtin{
+			Fn: b_printf,
+		},
+		"sprintf": &object.Builtin{
+			Fn: b_sprintf,
+		},
+		"read": &object.Builtin{
+			InterpreterFn: b_read,
+		},
+		"int": &object.Builtin{
+			Fn: b_int,
+		},
+		"str": &object.Builtin{
+			Fn: b_str,
+		},
+		"bytes": &object.Builtin{
+			Fn: b_bytes,
+		},
+		"type": &object.Builtin{
+			Fn: b_type,
+		},
+		"bool": &object.Builtin{
+			Fn: b_bool,
+		},
+		"is_int": &object.Builtin{
+			Fn: typePredicate(object.INTEGER_OBJ),
+		},
+		"is_float": &object.Builtin{
+			Fn: typePredicate(object.FLOAT_OBJ),
+		},
+		"is_number": &object.Builtin{
+			Fn: typePredicate(object.INTEGER_OBJ, object.FLOAT_OBJ),
+		},
+		"is_string": &object.Builtin{
+			Fn: typePredicate(object.STRING_OBJ),
+		},
+		"is_bytes": &object.Builtin{
+			Fn: typePredicate(object.BYTES_OBJ),
+		},
+		"is_bool": &object.Builtin{
+			Fn: typePredicate(object.BOOLEAN_OBJ),
+		},
+		"is_array": &object.Builtin{
+			Fn: typePredicate(object.ARRAY_OBJ),
+		},
+		"is_hash": &object.Builtin{
+			Fn: typePredicate(object.HASH_OBJ),
+		},
+		"is_null": &object.Builtin{
+			Fn: typePredicate(object.NULL_OBJ),
+		},
+		"is_function": &object.Builtin{
+			Fn: typePredicate(object.FUNCTION_OBJ, object.BUILTIN_OBJ),
+		},
+		"freeze": &object.Builtin{
+			Fn: b_freeze,
+		},
+		"push": &object.Builtin{
+			Fn: b_push,
+		},
+		"pop": &object.Builtin{
+			Fn: b_pop,
+		},
+		"shift": &object.Builtin{
+			Fn: b_shift,
+		},
+		"unshift": &object.Builtin{
+			Fn: b_unshift,
+		},
+		"first": &object.Builtin{
+			Fn: b_first,
+		},
+		"last": &object.Builtin{
+			Fn: b_last,
+		},
+		"rest": &object.Builtin{
+			Fn: b_rest,
+		},
+		"concat": &object.Builtin{
+			Fn: b_concat,
+		},
+		"reverse": &object.Builtin{
+			Fn: b_reverse,
+		},
+		"contains": &object.Builtin{
+			Fn: b_contains,
+		},
+		"index_of": &object.Builtin{
+			Fn: b_index_of,
+		},
+		"flatten": &object.Builtin{
+			Fn: b_flatten,
+		},
+		"zip": &object.Builtin{
+			Fn: b_zip,
+		},
+		"unique": &object.Builtin{
+			Fn: b_unique,
+		},
+		"chunk": &object.Builtin{
+			Fn: b_chunk,
+		},
+		"map": &object.Builtin{
+			InterpreterFn: b_map,
+		},
+		"filter": &object.Builtin{
+			InterpreterFn: b_filter,
+		},
+		"reduce": &object.Builtin{
+			InterpreterFn: b_reduce,
+		},
+		"each": &object.Builtin{
+			InterpreterFn: b_each,
+		},
+		"any": &object.Builtin{
+			InterpreterFn: b_any,
+		},
+		"all": &object.Builtin{
+			InterpreterFn: b_all,
+		},
+		"find": &object.Builtin{
+			InterpreterFn: b_find,
+		},
+		"sort": &object.Builtin{
+			InterpreterFn: b_sort,
+		},
+		"sort_by": &object.Builtin{
+			InterpreterFn: b_sort_by,
+		},
+	}
 }
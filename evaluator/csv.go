@@ -0,0 +1,303 @@
+package evaluator
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io/ioutil"
+	"monkey/object"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Every function of the csv module takes an optional hash of options:
+//
+//	delimiter    the field separator, "," by default
+//	header       when parsing, return an array of hashes keyed by the first
+//	             row; when writing hashes, true to write their sorted keys as
+//	             the first row, or an array with the columns to write
+//	lazy_quotes  accept quotes in unquoted fields and unescaped quotes
+//	comment      ignore the lines starting with this character
+//	trim_space   ignore the spaces at the start of each field
+//	quote_all    quote every field instead of only those that need it
+//	crlf         end lines with \r\n instead of \n
+func init() {
+	registerNativeModule("csv", map[string]object.Object{
+		"parse":      &object.Builtin{Fn: b_csv_parse},
+		"read_file":  &object.Builtin{InterpreterFn: b_csv_read_file},
+		"stringify":  &object.Builtin{Fn: b_csv_stringify},
+		"write_file": &object.Builtin{InterpreterFn: b_csv_write_file},
+	})
+}
+
+type csvOptions struct {
+	delimiter  rune
+	header     object.Object
+	lazyQuotes bool
+	comment    rune
+	trimSpace  bool
+	quoteAll   bool
+	crlf       bool
+}
+
+func parseCSVOptions(name string, args []object.Object, index int) (csvOptions, *object.Error) {
+	options := csvOptions{delimiter: ',', header: FALSE}
+
+	if len(args) <= index {
+		return options, nil
+	}
+
+	hash := args[index].(*object.Hash)
+
+	for _, pair := range hash.Pairs {
+		switch key := pair.Key.Inspect(); key {
+		case "delimiter", "comment":
+			str, ok := pair.Value.(*object.String)
+			if !ok || utf8.RuneCountInString(str.Value) != 1 {
+				return options, newError(name + ": the " + key + " option must be a single character")
+			}
+			r, _ := utf8.DecodeRuneInString(str.Value)
+			if key == "delimiter" {
+				options.delimiter = r
+			} else {
+				options.comment = r
+			}
+		case "header":
+			options.header = pair.Value
+		case "lazy_quotes":
+			options.lazyQuotes = isTruthy(pair.Value)
+		case "trim_space":
+			options.trimSpace = isTruthy(pair.Value)
+		case "quote_all":
+			options.quoteAll = isTruthy(pair.Value)
+		case "crlf":
+			options.crlf = isTruthy(pair.Value)
+		default:
+			return options, newError(name + ": unknown option " + key)
+		}
+	}
+
+	return options, nil
+}
+
+func b_csv_parse(args ...object.Object) object.Object {
+	if err := checkOptionalArgs("csv.parse", args, 1, object.STRING_OBJ, object.HASH_OBJ); err != nil {
+		return err
+	}
+
+	options, err := parseCSVOptions("csv.parse", args, 1)
+	if err != nil {
+		return err
+	}
+
+	return parseCSV("csv.parse", args[0].(*object.String).Value, options)
+}
+
+// b_csv_read_file parses a CSV file. It follows the same permissions as the
+// fs module.
+func b_csv_read_file(interp object.Interpreter, args ...object.Object) object.Object {
+	if err := checkOptionalArgs("csv.read_file", args, 1, object.STRING_OBJ, object.HASH_OBJ); err != nil {
+		return err
+	}
+
+	options, err := parseCSVOptions("csv.read_file", args, 1)
+	if err != nil {
+		return err
+	}
+
+	path, err := interp.(*Interpreter).Filesystem.checkPath("csv.read_file", args[0].(*object.String).Value, false)
+	if err != nil {
+		return err
+	}
+
+	content, readErr := ioutil.ReadFile(path)
+	if readErr != nil {
+		return fsError("csv.read_file", readErr)
+	}
+
+	return parseCSV("csv.read_file", string(content), options)
+}
+
+func parseCSV(name string, input string, options csvOptions) object.Object {
+	reader := csv.NewReader(strings.NewReader(input))
+	reader.Comma = options.delimiter
+	reader.Comment = options.comment
+	reader.LazyQuotes = options.lazyQuotes
+	reader.TrimLeadingSpace = options.trimSpace
+	reader.FieldsPerRecord = -1
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return newError(name + ": " + err.Error())
+	}
+
+	if !isTruthy(options.header) {
+		rows := make([]object.Object, len(records))
+		for i, record := range records {
+			rows[i] = stringArray(record)
+		}
+		return &object.Array{Elements: rows}
+	}
+
+	rows := []object.Object{}
+	if len(records) == 0 {
+		return &object.Array{Elements: rows}
+	}
+
+	header := records[0]
+	for _, record := range records[1:] {
+		pairs := make(map[string]object.Object, len(header))
+		for i, column := range header {
+			if i < len(record) {
+				pairs[column] = &object.String{Value: record[i]}
+			} else {
+				pairs[column] = NULL
+			}
+		}
+		rows = append(rows, newStringHash(pairs))
+	}
+
+	return &object.Array{Elements: rows}
+}
+
+// b_csv_stringify turns an array of arrays, or an array of hashes, into CSV.
+func b_csv_stringify(args ...object.Object) object.Object {
+	if err := checkOptionalArgs("csv.stringify", args, 1, object.ARRAY_OBJ, object.HASH_OBJ); err != nil {
+		return err
+	}
+
+	options, err := parseCSVOptions("csv.stringify", args, 1)
+	if err != nil {
+		return err
+	}
+
+	return writeCSV("csv.stringify", args[0].(*object.Array), options)
+}
+
+// b_csv_write_file writes rows to a file, following the permissions of the fs
+// module.
+func b_csv_write_file(interp object.Interpreter, args ...object.Object) object.Object {
+	if err := checkOptionalArgs("csv.write_file", args, 2, object.STRING_OBJ, object.ARRAY_OBJ, object.HASH_OBJ); err != nil {
+		return err
+	}
+
+	options, err := parseCSVOptions("csv.write_file", args, 2)
+	if err != nil {
+		return err
+	}
+
+	path, err := interp.(*Interpreter).Filesystem.checkPath("csv.write_file", args[0].(*object.String).Value, true)
+	if err != nil {
+		return err
+	}
+
+	content := writeCSV("csv.write_file", args[1].(*object.Array), options)
+	if isError(content) {
+		return content
+	}
+
+	if writeErr := ioutil.WriteFile(path, []byte(content.(*object.String).Value), 0644); writeErr != nil {
+		return fsError("csv.write_file", writeErr)
+	}
+
+	return NULL
+}
+
+func writeCSV(name string, rows *object.Array, options csvOptions) object.Object {
+	records, err := csvRecords(name, rows, options)
+	if err != nil {
+		return err
+	}
+
+	var result bytes.Buffer
+
+	if options.quoteAll {
+		newline := "\n"
+		if options.crlf {
+			newline = "\r\n"
+		}
+
+		for _, record := range records {
+			for i, field := range record {
+				if i > 0 {
+					result.WriteRune(options.delimiter)
+				}
+				result.WriteString(`"` + strings.ReplaceAll(field, `"`, `""`) + `"`)
+			}
+			result.WriteString(newline)
+		}
+
+		return &object.String{Value: result.String()}
+	}
+
+	writer := csv.NewWriter(&result)
+	writer.Comma = options.delimiter
+	writer.UseCRLF = options.crlf
+
+	if writeErr := writer.WriteAll(records); writeErr != nil {
+		return newError(name + ": " + writeErr.Error())
+	}
+
+	return &object.String{Value: result.String()}
+}
+
+// csvRecords converts the rows to be written into strings. Rows that are
+// hashes are written in the order of the header columns.
+func csvRecords(name string, rows *object.Array, options csvOptions) ([][]string, *object.Error) {
+	var columns []string
+
+	switch header := options.header.(type) {
+	case *object.Array:
+		for _, column := range header.Elements {
+			columns = append(columns, csvField(column))
+		}
+	default:
+		if isTruthy(header) && len(rows.Elements) > 0 {
+			if first, ok := rows.Elements[0].(*object.Hash); ok {
+				for _, pair := range first.Pairs {
+					columns = append(columns, csvField(pair.Key))
+				}
+				sort.Strings(columns)
+			}
+		}
+	}
+
+	records := [][]string{}
+	if columns != nil {
+		records = append(records, columns)
+	}
+
+	for _, row := range rows.Elements {
+		switch row := row.(type) {
+		case *object.Array:
+			record := make([]string, len(row.Elements))
+			for i, field := range row.Elements {
+				record[i] = csvField(field)
+			}
+			records = append(records, record)
+		case *object.Hash:
+			if columns == nil {
+				return nil, newError(name + ": writing hashes needs the header option")
+			}
+			record := make([]string, len(columns))
+			for i, column := range columns {
+				key := &object.String{Value: column}
+				if pair, ok := row.Pairs[key.HashKey()]; ok {
+					record[i] = csvField(pair.Value)
+				}
+			}
+			records = append(records, record)
+		default:
+			return nil, newError(name + ": every row must be an ARRAY or a HASH, got " + row.Type())
+		}
+	}
+
+	return records, nil
+}
+
+func csvField(obj object.Object) string {
+	if obj == NULL {
+		return ""
+	}
+	return obj.Inspect()
+}
@@ -4,7 +4,7 @@ import (
 	"fmt"
 	"monkey/ast"
 	"monkey/object"
-	"monkey/parser"
+	"strings"
 )
 
 var (
@@ -13,8 +13,6 @@ var (
 	NULL  = &object.Null{}
 )
 
-var libsEnv map[string]*object.Environment
-
 func newError(errorMsg string) *object.Error {
 	return &object.Error{Message: errorMsg}
 }
@@ -23,27 +21,50 @@ func isError(obj object.Object) bool {
 	return obj.Type() == object.ERROR_OBJ
 }
 
+func nativeBoolToBooleanObject(value bool) *object.Boolean {
+	if value {
+		return TRUE
+	}
+	return FALSE
+}
+
+func isTruthy(obj object.Object) bool {
+	return obj != NULL && obj != FALSE
+}
+
 func Eval(node ast.Node, env *object.Environment) object.Object {
 	switch node := node.(type) {
 	case *ast.Program:
+		// Functions keep a copy of the environment they are defined in, so the
+		// interpreter has to be in place before the first one is created.
+		interpreterOf(env)
 		return evalProgram(node.Statements, env)
 	case *ast.ExpressionStatement:
 		return Eval(node.Expression, env)
 	case *ast.UseStatement:
-		return evalUseStatement(node.Filename)
+		return evalUseStatement(node, env)
+	case *ast.ExportStatement:
+		return evalExportStatement(node, env)
 	case *ast.IntegerLiteral:
 		return &object.Integer{Value: node.Value}
+	case *ast.Null:
+		return NULL
 	case *ast.Boolean:
 		if node.Value {
 			return TRUE
 		}
 		return FALSE
+	case *ast.FloatLiteral:
+		return &object.Float{Value: node.Value}
 	case *ast.StringLiteral:
 		return &object.String{Value: node.Value}
 	case *ast.Array:
 		arr := &object.Array{}
 
 		arr.Elements = evalExpressions(node.Elements, env)
+		if len(arr.Elements) == 1 && isError(arr.Elements[0]) {
+			return arr.Elements[0]
+		}
 
 		return arr
 	case *ast.PrefixExpression:
@@ -53,16 +74,14 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		}
 		switch node.Operator {
 		case "!":
-			if right == TRUE {
-				return FALSE
-			} else if right == FALSE {
-				return TRUE
-			} else {
-				return FALSE
-			}
+			return nativeBoolToBooleanObject(!isTruthy(right))
 		case "-":
+			if float, ok := right.(*object.Float); ok {
+				return &object.Float{Value: -float.Value}
+			}
+
 			if right.Type() != object.INTEGER_OBJ {
-				return newError("expected the right member to be an integer, got a " + right.Type() + " instead")
+				return newError("expected the right member to be a number, got a " + right.Type() + " instead")
 			}
 
 			originalValue := right.(*object.Integer).Value
@@ -75,11 +94,26 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		if isError(left) {
 			return left
 		}
+
+		if node.Operator == "??" {
+			if left != NULL {
+				return left
+			}
+			return Eval(node.Right, env)
+		}
+
 		right := Eval(node.Right, env)
 		if isError(right) {
 			return right
 		}
 
+		switch node.Operator {
+		case "==":
+			return nativeBoolToBooleanObject(object.Equal(left, right))
+		case "!=":
+			return nativeBoolToBooleanObject(!object.Equal(left, right))
+		}
+
 		if left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ {
 			leftInt := left.(*object.Integer).Value
 			rightInt := right.(*object.Integer).Value
@@ -93,18 +127,6 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 				return &object.Integer{Value: leftInt * rightInt}
 			case "/":
 				return &object.Integer{Value: leftInt / rightInt}
-			case "==":
-				if leftInt == rightInt {
-					return TRUE
-				} else {
-					return FALSE
-				}
-			case "!=":
-				if leftInt == rightInt {
-					return FALSE
-				} else {
-					return TRUE
-				}
 			case ">":
 				if leftInt > rightInt {
 					return TRUE
@@ -118,21 +140,10 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 					return FALSE
 				}
 			}
+		} else if isNumber(left) && isNumber(right) {
+			return evalFloatInfixExpression(node.Operator, toFloat(left), toFloat(right))
 		} else if left.Type() == object.BOOLEAN_OBJ && right.Type() == object.BOOLEAN_OBJ {
-			switch node.Operator {
-			case "==":
-				if left == right {
-					return TRUE
-				} else {
-					return FALSE
-				}
-			case "!=":
-				if left != right {
-					return TRUE
-				} else {
-					return FALSE
-				}
-			}
+			return newError("unknown operator: BOOLEAN " + node.Operator + " BOOLEAN")
 		} else if left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ {
 			leftString := left.(*object.String).Value
 			rightString := right.(*object.String).Value
@@ -140,19 +151,13 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 			switch node.Operator {
 			case "+":
 				return &object.String{Value: leftString + rightString}
-			case "==":
-				if leftString == rightString {
-					return TRUE
-				} else {
-					return FALSE
-				}
-			case "!=":
-				if leftString != rightString {
-					return TRUE
-				} else {
-					return FALSE
-				}
 			}
+		} else if left.Type() == object.ARRAY_OBJ && right.Type() == object.ARRAY_OBJ && node.Operator == "+" {
+			return b_concat(left, right)
+		} else if left.Type() == object.BYTES_OBJ && right.Type() == object.BYTES_OBJ && node.Operator == "+" {
+			return &object.Bytes{Value: append(append([]byte{}, left.(*object.Bytes).Value...), right.(*object.Bytes).Value...)}
+		} else if left.Type() == object.TIME_OBJ {
+			return evalTimeInfixExpression(node.Operator, left.(*object.Time), right)
 		} else {
 			return newError("left and right values have different types")
 		}
@@ -163,15 +168,19 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		}
 
 		if condition != NULL && condition != FALSE {
-			return Eval(&node.TrueBlock, env)
+			return Eval(&node.TrueBlock, object.NewExtendedEnvironment(env))
 		} else if len(node.FalseBlock.Statements) > 0 {
-			return Eval(&node.FalseBlock, env)
+			return Eval(&node.FalseBlock, object.NewExtendedEnvironment(env))
 		} else {
 			return NULL
 		}
 	case *ast.BlockStatement:
 		return evalBlockStatement(node, env)
 	case *ast.ReturnStatement:
+		if call, ok := node.Value.(*ast.CallExpression); ok {
+			return evalTailCall(call, env)
+		}
+
 		val := Eval(node.Value, env)
 		if isError(val) {
 			return val
@@ -179,8 +188,8 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		return &object.ReturnValue{Value: val}
 	case *ast.LetStatement:
 
-		if varExists := env.Get(node.Name.Value); varExists != nil {
-			return newError("variable already declared")
+		if err := checkRedeclaration(node.Name.Value, env); err != nil {
+			return err
 		}
 
 		val := Eval(node.Value, env)
@@ -188,6 +197,10 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 			return val
 		}
 
+		if node.Constant {
+			return env.SetConstant(node.Name.Value, val)
+		}
+
 		return env.Set(node.Name.Value, val)
 	case *ast.Identifier:
 		val := env.Get(node.Value)
@@ -206,78 +219,54 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 			return newError("invalid identifier: " + node.Variable.Value)
 		}
 
+		if env.IsConstant(node.Variable.Value) {
+			return newError("cannot reassign constant " + node.Variable.Value)
+		}
+
 		val := Eval(node.NewValue, env)
 		if isError(val) {
 			return val
 		}
 
-		return env.Set(node.Variable.Value, val)
+		return env.Assign(node.Variable.Value, val)
 
 	case *ast.FunctionLiteral:
-		funcLiteral := &object.Function{Parameters: node.Parameters, Body: node.Block, Env: *env}
+		funcLiteral := &object.Function{
+			Name:       node.Name.Value,
+			Parameters: node.Parameters,
+			Defaults:   node.Defaults,
+			Rest:       node.Rest,
+			Body:       node.Block,
+			Env:        *env,
+		}
 
 		if node.Name.Value != "" {
-			env.Set(node.Name.Value, funcLiteral)
+			if err := checkRedeclaration(node.Name.Value, env); err != nil {
+				return err
+			}
+			env.SetConstant(node.Name.Value, funcLiteral)
 		}
 
 		return funcLiteral
-	case *ast.CallExpression:
-
-		var function object.Object
-
-		if node.Lib != "" {
-			function = Eval(node.Function, libsEnv[node.Lib])
-		} else {
-			function = Eval(node.Function, env)
-		}
-
-		if isError(function) {
-			return function
-		}
-
-		args := evalExpressions(node.Arguments, env)
-		if len(args) == 1 && isError(args[0]) {
-			return args[0]
-		}
-
-		return callFunction(function, args)
-	case *ast.ArrayAccessExpression:
-		array := Eval(node.Array, env)
-		if isError(array) {
-			return array
-		}
-
-		if array.Type() != object.ARRAY_OBJ {
-			return newError("expected left member to be an array, got " + array.Type() + " instead")
-		}
-		arrayElements := array.(*object.Array).Elements
-
-		position := Eval(node.Position, env)
-		if isError(position) {
-			return position
-		}
-
-		if position.Type() != object.INTEGER_OBJ {
-			return newError("expected position to be an integer, got " + position.Type() + " instead")
-		}
-
-		pos := position.(*object.Integer).Value
-
-		if pos >= int64(len(arrayElements)) {
-			return newError(fmt.Sprintf("index out of range, array's length is %d", len(arrayElements)))
-		}
-
-		value := arrayElements[pos]
+	case *ast.CallExpression, *ast.ArrayAccessExpression, *ast.SliceExpression, *ast.ExternalReferenceExpression:
+		value, _ := evalChainLeft(node.(ast.Expression), env)
 		return value
+	case *ast.HashLiteral:
+		return evalHashLiteral(node, env)
 	case *ast.WhileStatement:
 		condition := Eval(node.Condition, env)
 		if isError(condition) {
 			return condition
 		}
 
+		ctx := interpreterOf(env).Context
+
 		for condition != NULL && condition != FALSE {
+			if err := ctx.Err(); err != nil {
+				return newError("interrupted: " + err.Error())
+			}
 
-			result := Eval(&node.Block, env)
+			result := Eval(&node.Block, object.NewExtendedEnvironment(env))
 			if result.Type() == object.RETURN_VALUE_OBJ || result.Type() == object.ERROR_OBJ {
 				return result
 			}
@@ -289,26 +278,210 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		}
 
 		return NULL
-	case *ast.ExternalReferenceExpression:
+	case *ast.SpreadExpression:
+		return newError("the spread operator can only be used in call arguments and array literals")
+	case *ast.NamedArgument:
+		return newError("named arguments can only be used in function calls")
+	}
+	return nil
+}
 
-		switch ref := node.Referece.(type) {
-		case *ast.CallExpression:
-			ref.Lib = node.Module
+// checkRedeclaration returns an error if name is already bound in the
+// innermost scope of env. Shadowing a binding of an outer scope is fine.
+func checkRedeclaration(name string, env *object.Environment) *object.Error {
+	if env.GetLocal(name) == nil {
+		return nil
+	}
+	if env.IsConstant(name) {
+		return newError("cannot redeclare constant " + name)
+	}
+	return newError("variable already declared")
+}
 
-			result := Eval(ref, env)
+func evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Object {
+	hash := object.NewHash()
 
-			return result
-		case *ast.Identifier:
-			return Eval(ref, libsEnv[node.Module])
+	for i, keyNode := range node.Keys {
+		key := Eval(keyNode, env)
+		if isError(key) {
+			return key
+		}
+
+		hashable, ok := key.(object.Hashable)
+		if !ok {
+			return newError("unusable as hash key: " + key.Type())
 		}
 
+		value := Eval(node.Values[i], env)
+		if isError(value) {
+			return value
+		}
+
+		hash.Pairs[hashable.HashKey()] = object.HashPair{Key: key, Value: value}
 	}
-	return nil
+
+	return hash
 }
 
-func evalProgram(statements []ast.Statement, env *object.Environment) object.Object {
-	libsEnv = make(map[string]*object.Environment)
+func evalHashAccess(hash *object.Hash, key object.Object) object.Object {
+	if _, ok := key.(object.Hashable); !ok {
+		return newError("unusable as hash key: " + key.Type())
+	}
+
+	value, ok := hash.Get(key)
+	if !ok {
+		return NULL
+	}
+
+	return value
+}
+
+// evalIndexAccess returns the element of an array, the character of a string
+// or the byte, as an integer, at the given position.
+func evalIndexAccess(left, position object.Object) object.Object {
+	var chars []rune
+	var length int
+
+	switch left := left.(type) {
+	case *object.Array:
+		length = len(left.Elements)
+	case *object.String:
+		chars = []rune(left.Value)
+		length = len(chars)
+	case *object.Bytes:
+		length = len(left.Value)
+	default:
+		return newError("expected left member to be an array, a string, bytes or a hash, got " + left.Type() + " instead")
+	}
+
+	if position.Type() != object.INTEGER_OBJ {
+		return newError("expected position to be an integer, got " + position.Type() + " instead")
+	}
+
+	pos := position.(*object.Integer).Value
+
+	if pos < 0 || pos >= int64(length) {
+		return newError(fmt.Sprintf("index out of range, %s's length is %d", strings.ToLower(left.Type()), length))
+	}
+
+	switch left := left.(type) {
+	case *object.Array:
+		return left.Elements[pos]
+	case *object.String:
+		return &object.String{Value: string(chars[pos])}
+	default:
+		return &object.Integer{Value: int64(left.(*object.Bytes).Value[pos])}
+	}
+}
+
+// evalSliceExpression evaluates `x[start:end]` on arrays, strings and bytes.
+// The result is always a copy, and strings are sliced by characters.
+func evalSliceExpression(node *ast.SliceExpression, env *object.Environment) (object.Object, bool) {
+	left, skipped := evalChainLink(node.Left, node.Optional, env)
+	if skipped || isError(left) {
+		return left, skipped
+	}
+
+	return evalSlice(node, left, env), false
+}
+
+func evalSlice(node *ast.SliceExpression, left object.Object, env *object.Environment) object.Object {
+	var chars []rune
+	var length int
+
+	switch left := left.(type) {
+	case *object.Array:
+		length = len(left.Elements)
+	case *object.String:
+		chars = []rune(left.Value)
+		length = len(chars)
+	case *object.Bytes:
+		length = len(left.Value)
+	default:
+		return newError("expected left member to be an array, a string or bytes, got " + left.Type() + " instead")
+	}
+
+	start, end := int64(0), int64(length)
+
+	if node.Start != nil {
+		value, err := evalSliceBound(node.Start, env)
+		if err != nil {
+			return err
+		}
+		start = value
+	}
+
+	if node.End != nil {
+		value, err := evalSliceBound(node.End, env)
+		if err != nil {
+			return err
+		}
+		end = value
+	}
+
+	if start < 0 || end > int64(length) || start > end {
+		return newError(fmt.Sprintf("slice bounds out of range [%d:%d] with length %d", start, end, length))
+	}
+
+	switch left := left.(type) {
+	case *object.Array:
+		elements := make([]object.Object, end-start)
+		copy(elements, left.Elements[start:end])
+		return &object.Array{Elements: elements}
+	case *object.String:
+		return &object.String{Value: string(chars[start:end])}
+	default:
+		return &object.Bytes{Value: append([]byte{}, left.(*object.Bytes).Value[start:end]...)}
+	}
+}
+
+func evalSliceBound(node ast.Expression, env *object.Environment) (int64, object.Object) {
+	bound := Eval(node, env)
+	if isError(bound) {
+		return 0, bound
+	}
+
+	if bound.Type() != object.INTEGER_OBJ {
+		return 0, newError("expected slice bound to be an integer, got " + bound.Type() + " instead")
+	}
 
+	return bound.(*object.Integer).Value, nil
+}
+
+func evalFloatInfixExpression(operator string, left, right float64) object.Object {
+	switch operator {
+	case "+":
+		return &object.Float{Value: left + right}
+	case "-":
+		return &object.Float{Value: left - right}
+	case "*":
+		return &object.Float{Value: left * right}
+	case "/":
+		return &object.Float{Value: left / right}
+	case ">":
+		return nativeBoolToBooleanObject(left > right)
+	case "<":
+		return nativeBoolToBooleanObject(left < right)
+	default:
+		return newError("unknown operator: FLOAT " + operator + " FLOAT")
+	}
+}
+
+func isNumber(obj object.Object) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.FLOAT_OBJ
+}
+
+func toFloat(obj object.Object) float64 {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return float64(obj.Value)
+	case *object.Float:
+		return obj.Value
+	}
+	return 0
+}
+
+func evalProgram(statements []ast.Statement, env *object.Environment) object.Object {
 	var result object.Object
 
 	for _, s := range statements {
@@ -316,6 +489,9 @@ func evalProgram(statements []ast.Statement, env *object.Environment) object.Obj
 
 		switch result := result.(type) {
 		case *object.ReturnValue:
+			if tailCall, ok := result.Value.(*object.TailCall); ok {
+				return callFunction(interpreterOf(env), tailCall.Function, tailCall.Arguments, tailCall.Named)
+			}
 			return result.Value
 		case *object.Error:
 			return result
@@ -325,18 +501,152 @@ func evalProgram(statements []ast.Statement, env *object.Environment) object.Obj
 	return result
 }
 
-func evalUseStatement(filename string) object.Object {
-	fileAst := parser.ParseFile(filename)
+func evalUseStatement(node *ast.UseStatement, env *object.Environment) object.Object {
+	in := interpreterOf(env)
 
-	fileEnv := object.NewEnvironment()
+	loaded := in.Modules.load(in, node.Filename)
+	if isError(loaded) {
+		return loaded
+	}
 
-	result := Eval(fileAst, fileEnv)
+	module := loaded.(*object.Module)
 
-	libsEnv[filename] = fileEnv
+	if node.Names != nil {
+		for _, name := range node.Names {
+			value := moduleMember(module, name.Value)
+			if isError(value) {
+				return value
+			}
+			if err := checkRedeclaration(name.Value, env); err != nil {
+				return err
+			}
+			env.Set(name.Value, value)
+		}
+		return NULL
+	}
+
+	name := module.Name
+	if node.Alias != "" {
+		name = node.Alias
+	}
+
+	if err := checkRedeclaration(name, env); err != nil {
+		return err
+	}
+
+	return env.Set(name, module)
+}
+
+func evalExportStatement(node *ast.ExportStatement, env *object.Environment) object.Object {
+	if !env.IsTopLevel() {
+		return newError("export is only allowed at the top level of a module")
+	}
+
+	var name string
+
+	switch statement := node.Statement.(type) {
+	case *ast.LetStatement:
+		name = statement.Name.Value
+	case *ast.ExpressionStatement:
+		function, ok := statement.Expression.(*ast.FunctionLiteral)
+		if !ok {
+			return newError("only let, const and named fn declarations can be exported")
+		}
+		name = function.Name.Value
+	}
+
+	result := Eval(node.Statement, env)
+	if isError(result) {
+		return result
+	}
+
+	env.Export(name)
 
 	return result
 }
 
+// evalChainLeft evaluates a member access, index, slice or call, and
+// reports whether it was skipped. Once an optional link such as a?.b or a?[0]
+// finds null, the rest of the chain is skipped and evaluates to null, so
+// a?.b.c(1)[0] is null when a is.
+func evalChainLeft(node ast.Expression, env *object.Environment) (object.Object, bool) {
+	switch node := node.(type) {
+	case *ast.ExternalReferenceExpression:
+		return evalExternalReference(node, env)
+	case *ast.ArrayAccessExpression:
+		return evalIndexExpression(node, env)
+	case *ast.SliceExpression:
+		return evalSliceExpression(node, env)
+	case *ast.CallExpression:
+		function, args, named, skipped := evalCall(node, env)
+		if skipped || isError(function) {
+			return function, skipped
+		}
+		return callFunction(interpreterOf(env), function, args, named), false
+	default:
+		return Eval(node, env), false
+	}
+}
+
+// evalChainLink evaluates the left side of a link of a chain, reporting
+// whether the link must be skipped.
+func evalChainLink(left ast.Expression, optional bool, env *object.Environment) (object.Object, bool) {
+	value, skipped := evalChainLeft(left, env)
+	if skipped || (optional && value == NULL) {
+		return NULL, true
+	}
+	return value, false
+}
+
+// evalExternalReference resolves `x.name`, which is either a member of a
+// module or the "name" key of a hash.
+func evalExternalReference(node *ast.ExternalReferenceExpression, env *object.Environment) (object.Object, bool) {
+	left, skipped := evalChainLink(node.Left, node.Optional, env)
+	if skipped || isError(left) {
+		return left, skipped
+	}
+
+	ref, ok := node.Referece.(*ast.Identifier)
+	if !ok {
+		return newError("invalid reference " + node.String()), false
+	}
+
+	switch left := left.(type) {
+	case *object.Module:
+		return moduleMember(left, ref.Value), false
+	case *object.Hash:
+		return evalHashAccess(left, &object.String{Value: ref.Value}), false
+	default:
+		return newError("expected left member to be a module or a hash, got " + left.Type() + " instead"), false
+	}
+}
+
+func evalIndexExpression(node *ast.ArrayAccessExpression, env *object.Environment) (object.Object, bool) {
+	array, skipped := evalChainLink(node.Array, node.Optional, env)
+	if skipped || isError(array) {
+		return array, skipped
+	}
+
+	position := Eval(node.Position, env)
+	if isError(position) {
+		return position, false
+	}
+
+	if hash, ok := array.(*object.Hash); ok {
+		return evalHashAccess(hash, position), false
+	}
+
+	return evalIndexAccess(array, position), false
+}
+
+func moduleMember(module *object.Module, name string) object.Object {
+	if !module.Env.IsExported(name) {
+		return newError("module " + module.Name + " has no exported member " + name)
+	}
+
+	return module.Env.Get(name)
+}
+
 func evalBlockStatement(block *ast.BlockStatement, env *object.Environment) object.Object {
 	var result object.Object
 
@@ -356,6 +666,21 @@ func evalExpressions(nodes []ast.Expression, env *object.Environment) []object.O
 	var arguments []object.Object
 
 	for _, a := range nodes {
+		if spread, ok := a.(*ast.SpreadExpression); ok {
+			result := Eval(spread.Value, env)
+			if isError(result) {
+				return []object.Object{result}
+			}
+
+			array, ok := result.(*object.Array)
+			if !ok {
+				return []object.Object{newError("expected the spread value to be an array, got " + result.Type() + " instead")}
+			}
+
+			arguments = append(arguments, array.Elements...)
+			continue
+		}
+
 		result := Eval(a, env)
 
 		if isError(result) {
@@ -368,28 +693,168 @@ func evalExpressions(nodes []ast.Expression, env *object.Environment) []object.O
 	return arguments
 }
 
-func callFunction(fn object.Object, args []object.Object) object.Object {
+func evalArguments(nodes []ast.Expression, env *object.Environment) ([]object.Object, map[string]object.Object) {
+	var positional []ast.Expression
+	var named map[string]object.Object
+
+	for _, a := range nodes {
+		argument, ok := a.(*ast.NamedArgument)
+		if !ok {
+			if named != nil {
+				return []object.Object{newError("positional argument follows a named argument")}, nil
+			}
+			positional = append(positional, a)
+			continue
+		}
+
+		if named == nil {
+			named = make(map[string]object.Object)
+		}
+
+		if _, exists := named[argument.Name.Value]; exists {
+			return []object.Object{newError("argument " + argument.Name.Value + " given more than once")}, nil
+		}
+
+		value := Eval(argument.Value, env)
+		if isError(value) {
+			return []object.Object{value}, nil
+		}
 
-	if builtinFn, ok := fn.(*object.Builtin); ok {
-		return builtinFn.Fn(args...)
+		named[argument.Name.Value] = value
 	}
 
-	function, ok := fn.(*object.Function)
-	if !ok {
-		return newError("expected a function, got " + function.Type() + " instead")
+	return evalExpressions(positional, env), named
+}
+
+// evalCall evaluates the function and the arguments of a call without calling
+// it. If anything fails, the error is returned as the function. The call is
+// skipped, without evaluating its arguments, when it ends a chain that found
+// null, as in a?.f(x).
+func evalCall(node *ast.CallExpression, env *object.Environment) (object.Object, []object.Object, map[string]object.Object, bool) {
+	function, skipped := evalChainLeft(node.Function, env)
+	if skipped || isError(function) {
+		return function, nil, nil, skipped
 	}
 
-	extendedEnv := object.NewExtendedEnvironment(&function.Env)
+	args, named := evalArguments(node.Arguments, env)
+	if len(args) == 1 && isError(args[0]) {
+		return args[0], nil, nil, false
+	}
+
+	return function, args, named, false
+}
 
-	for i, arg := range args {
-		extendedEnv.Set(function.Parameters[i].Value, arg)
+func evalTailCall(node *ast.CallExpression, env *object.Environment) object.Object {
+	function, args, named, skipped := evalCall(node, env)
+	if skipped {
+		return &object.ReturnValue{Value: NULL}
+	}
+	if isError(function) {
+		return function
 	}
 
-	evaluated := Eval(&function.Body, extendedEnv)
+	return &object.ReturnValue{Value: &object.TailCall{Function: function, Arguments: args, Named: named}}
+}
+
+// callFunction calls fn and keeps calling the functions it returns in tail
+// position, so tail recursive functions run in constant Go stack.
+func callFunction(in *Interpreter, fn object.Object, args []object.Object, named map[string]object.Object) object.Object {
+
+	for {
+		if err := in.Context.Err(); err != nil {
+			return newError("interrupted: " + err.Error())
+		}
+
+		if builtinFn, ok := fn.(*object.Builtin); ok {
+			if len(named) > 0 {
+				return newError("builtin functions do not accept named arguments")
+			}
+			if builtinFn.InterpreterFn != nil {
+				return builtinFn.InterpreterFn(in, args...)
+			}
+			return builtinFn.Fn(args...)
+		}
 
-	if returnValue, ok := evaluated.(*object.ReturnValue); ok {
-		return returnValue.Value
+		function, ok := fn.(*object.Function)
+		if !ok {
+			return newError("expected a function, got " + fn.Type() + " instead")
+		}
+
+		extendedEnv, err := bindArguments(function, args, named)
+		if err != nil {
+			return err
+		}
+
+		evaluated := Eval(&function.Body, extendedEnv)
+
+		if returnValue, ok := evaluated.(*object.ReturnValue); ok {
+			evaluated = returnValue.Value
+		}
+
+		tailCall, ok := evaluated.(*object.TailCall)
+		if !ok {
+			return evaluated
+		}
+
+		fn, args, named = tailCall.Function, tailCall.Arguments, tailCall.Named
+	}
+}
+
+func bindArguments(function *object.Function, args []object.Object, named map[string]object.Object) (*object.Environment, *object.Error) {
+	name := function.Name
+	if name == "" {
+		name = "anonymous function"
+	}
+
+	if function.Rest == nil && len(args) > len(function.Parameters) {
+		return nil, newError(fmt.Sprintf("%s: wrong number of arguments, want at most %d, got %d", name, len(function.Parameters), len(args)))
+	}
+
+	for argName := range named {
+		if !hasParameter(function, argName) {
+			return nil, newError(fmt.Sprintf("%s: unknown argument %s", name, argName))
+		}
+	}
+
+	extendedEnv := object.NewExtendedEnvironment(&function.Env)
+
+	for i, param := range function.Parameters {
+		value, isNamed := named[param.Value]
+
+		switch {
+		case i < len(args) && isNamed:
+			return nil, newError(fmt.Sprintf("%s: argument %s given more than once", name, param.Value))
+		case i < len(args):
+			value = args[i]
+		case isNamed:
+		case function.Defaults[param.Value] != nil:
+			value = Eval(function.Defaults[param.Value], extendedEnv)
+			if isError(value) {
+				return nil, value.(*object.Error)
+			}
+		default:
+			return nil, newError(fmt.Sprintf("%s: missing argument %s", name, param.Value))
+		}
+
+		extendedEnv.Set(param.Value, value)
+	}
+
+	if function.Rest != nil {
+		rest := &object.Array{Elements: []object.Object{}}
+		if len(args) > len(function.Parameters) {
+			rest.Elements = append(rest.Elements, args[len(function.Parameters):]...)
+		}
+		extendedEnv.Set(function.Rest.Value, rest)
 	}
 
-	return evaluated
+	return extendedEnv, nil
+}
+
+func hasParameter(function *object.Function, name string) bool {
+	for _, param := range function.Parameters {
+		if param.Value == name {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,221 @@
+package evaluator
+
+import (
+	"bufio"
+	"context"
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/parser"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testEval(t *testing.T, input string) object.Object {
+	t.Helper()
+
+	l := lexer.New(input, bufio.NewScanner(strings.NewReader("")))
+	p := parser.New(l)
+	program := p.ParseProgram()
+
+	if len(p.Errors()) > 0 {
+		t.Fatalf("parser errors for %q: %v", input, p.Errors())
+	}
+
+	return Eval(program, object.NewEnvironment())
+}
+
+func testInteger(t *testing.T, obj object.Object, expected int64) {
+	t.Helper()
+
+	integer, ok := obj.(*object.Integer)
+	if !ok {
+		t.Fatalf("expected an integer, got %T (%s)", obj, inspect(obj))
+	}
+
+	if integer.Value != expected {
+		t.Errorf("expected %d, got %d", expected, integer.Value)
+	}
+}
+
+func inspect(obj object.Object) string {
+	if obj == nil {
+		return "nil"
+	}
+	return obj.Inspect()
+}
+
+func TestTailCalls(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{
+			`let count = fn(n, acc) { if (n == 0) { return acc }; return count(n - 1, acc + 1) };
+			count(1000000, 0)`,
+			1000000,
+		},
+		{
+			`let down = fn(n) { if (n > 0) { return down(n - 1) } else { return n } };
+			down(1000000)`,
+			0,
+		},
+		{
+			`let loop = fn(n) { while (n > 0) { return loop(n - 1) }; return 42 };
+			loop(1000000)`,
+			42,
+		},
+		{
+			`let even = fn(n) { if (n == 0) { return 1 }; return odd(n - 1) };
+			let odd = fn(n) { if (n == 0) { return 0 }; return even(n - 1) };
+			even(1000000)`,
+			1,
+		},
+		{
+			`let size = fn(arr) { return len(arr) };
+			size([1, 2, 3])`,
+			3,
+		},
+		{
+			`return len("four")`,
+			4,
+		},
+	}
+
+	for _, tt := range tests {
+		testInteger(t, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func testError(t *testing.T, obj object.Object, expected string) {
+	t.Helper()
+
+	err, ok := obj.(*object.Error)
+	if !ok {
+		t.Fatalf("expected an error, got %T (%s)", obj, inspect(obj))
+	}
+
+	if err.Message != expected {
+		t.Errorf("expected error %q, got %q", expected, err.Message)
+	}
+}
+
+func TestScoping(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		// Each iteration gets a fresh scope, so let doesn't see the previous x.
+		{`let i = 0; let total = 0;
+		while (i < 3) { let x = i * 2; total = total + x; i = i + 1 };
+		total`, 6},
+		// A let in a block shadows the outer binding without changing it.
+		{`let a = 1; if (true) { let a = 2 }; a`, 1},
+		{`let a = 1; if (true) { let a = 2; a } else { 0 }`, 2},
+		{`let a = 1; let f = fn() { let a = 3; a }; f() + a`, 4},
+		// Reassignment writes to the scope that defined the variable.
+		{`let b = 1; if (true) { b = 5 }; b`, 5},
+		{`let b = 1; if (true) { if (true) { b = b + 1 } }; b`, 2},
+		{`let b = 1; let f = fn() { b = 7 }; f(); b`, 7},
+		{`let b = 1; if (true) { let b = 2; b = 3 }; b`, 1},
+		// Constants can be shadowed by a new binding in an inner scope.
+		{`const c = 1; if (true) { let c = 2 }; c`, 1},
+		{`const c = 1; if (true) { let c = 2; c = 3; c } else { 0 }`, 3},
+		{`let d = 1; if (true) { const d = 2; d } else { 0 }`, 2},
+		{`fn f() { 1 }; if (true) { fn f() { 2 }; f() } else { 0 }`, 2},
+		{`const s = 1; if (true) { use strings as s; len(s.upper("ab")) } else { 0 }`, 2},
+	}
+
+	for _, tt := range tests {
+		testInteger(t, testEval(t, tt.input), tt.expected)
+	}
+
+	errors := []struct {
+		input    string
+		expected string
+	}{
+		{`let i = 0; while (i < 2) { let x = 1; let x = 2; i = i + 1 }`, "variable already declared"},
+		{`const c = 1; if (true) { c = 3 }`, "cannot reassign constant c"},
+		{`const c = 1; let c = 2`, "cannot redeclare constant c"},
+		{`if (true) { let e = 1 }; e = 2`, "invalid identifier: e"},
+		{`const x = 1; fn x() { 2 }`, "cannot redeclare constant x"},
+		{`let x = 1; fn x() { 2 }`, "variable already declared"},
+		{`fn foo() { 1 }; fn foo() { 2 }`, "cannot redeclare constant foo"},
+		{`fn foo() { 1 }; let foo = 2`, "cannot redeclare constant foo"},
+		{`const s = 1; use strings as s`, "cannot redeclare constant s"},
+		{`let strings = 1; use strings`, "variable already declared"},
+		{`const upper = 1; use strings { upper }`, "cannot redeclare constant upper"},
+	}
+
+	for _, tt := range errors {
+		testError(t, testEval(t, tt.input), tt.expected)
+	}
+}
+
+func TestContextStopsRecursion(t *testing.T) {
+	tests := []string{
+		`let f = fn(n) { return f(n + 1) }; f(0)`,
+		`let f = fn(n) { 1 + f(n + 1) }; f(0)`,
+		`let f = fn(n) { while (true) { return f(n + 1) } }; f(0)`,
+	}
+
+	for _, input := range tests {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+
+		in := NewInterpreter()
+		in.Context = ctx
+
+		_, result := evalIn(t, input, in)
+		testError(t, result, "interrupted: context deadline exceeded")
+
+		cancel()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	sleeping := NewInterpreter()
+	sleeping.Context = ctx
+
+	_, result := evalIn(t, `use time; time.sleep(10000)`, sleeping)
+	testError(t, result, "time.sleep: interrupted: context deadline exceeded")
+
+	// Cancelling one interpreter leaves the others running.
+	testInteger(t, testEval(t, `let f = fn(n) { if (n == 0) { return 0 }; return f(n - 1) }; f(10)`), 0)
+}
+
+func TestCyclicArrays(t *testing.T) {
+	result := testEval(t, `let a = [1]; push(a, a); let h = {"a": a}; push(a, h); str(a)`)
+	testString(t, result, "[1, [...], {a: [...]}]")
+
+	result = testEval(t, `let a = [1]; push(a, a); flatten(a)`)
+	testError(t, result, "flatten: cannot flatten an array that contains itself")
+
+	result = testEval(t, `let a = [1]; push(a, a); flatten(a, 100000000)`)
+	testError(t, result, "flatten: cannot flatten an array that contains itself")
+
+	result = testEval(t, `let b = [1]; let a = [b, b, [b]]; len(flatten(a))`)
+	testInteger(t, result, 3)
+}
+
+func TestOptionalChaining(t *testing.T) {
+	nulls := []string{
+		`let a = null; a?.b.c`,
+		`let a = null; a?.b.c(1)[0]`,
+		`let a = null; a?[0].b`,
+		`let a = null; a?[0:1][0]`,
+		`let a = {"b": null}; a.b?.c.d`,
+		`let a = null; let f = fn() { return a?.b() }; f()`,
+	}
+
+	for _, input := range nulls {
+		if result := testEval(t, input); result != NULL {
+			t.Errorf("expected %q to be null, got %s", input, inspect(result))
+		}
+	}
+
+	testInteger(t, testEval(t, `let a = null; let log = []; a?.f(push(log, 1)); len(log)`), 0)
+	testInteger(t, testEval(t, `let a = {"b": {"c": [1, 2]}}; a?.b.c[1]`), 2)
+	testInteger(t, testEval(t, `let a = null; a?.b.c ?? 3`), 3)
+	testError(t, testEval(t, `let a = {}; a.b.c`), "expected left member to be a module or a hash, got NULL instead")
+}
@@ -0,0 +1,136 @@
+package evaluator
+
+import (
+	"bytes"
+	"fmt"
+	"monkey/object"
+	"strings"
+)
+
+func b_print(args ...object.Object) object.Object {
+	fmt.Print(joinInspect(args))
+	return NULL
+}
+
+func b_println(args ...object.Object) object.Object {
+	fmt.Println(joinInspect(args))
+	return NULL
+}
+
+func b_printf(args ...object.Object) object.Object {
+	formatted := b_sprintf(args...)
+	if isError(formatted) {
+		return formatted
+	}
+
+	fmt.Print(formatted.(*object.String).Value)
+	return NULL
+}
+
+func b_sprintf(args ...object.Object) object.Object {
+	if len(args) == 0 {
+		return newError("sprintf: want a format string")
+	}
+
+	format, ok := args[0].(*object.String)
+	if !ok {
+		return newError("sprintf: the format must be a STRING, got " + args[0].Type())
+	}
+
+	result, err := formatObjects(format.Value, args[1:])
+	if err != nil {
+		return err
+	}
+
+	return &object.String{Value: result}
+}
+
+func joinInspect(args []object.Object) string {
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		parts[i] = arg.Inspect()
+	}
+	return strings.Join(parts, " ")
+}
+
+// formatObjects works like fmt.Sprintf for the %d, %s, %v, %x, %X, %f, %e,
+// %g, %q and %t verbs, with their flags, width and precision. Every verb
+// must have a matching argument, and every argument a verb.
+func formatObjects(format string, args []object.Object) (string, *object.Error) {
+	var result bytes.Buffer
+	next := 0
+
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			result.WriteByte(format[i])
+			continue
+		}
+
+		start := i
+		i++
+		for i < len(format) && strings.IndexByte("+-# 0123456789.", format[i]) >= 0 {
+			i++
+		}
+
+		if i >= len(format) {
+			return "", newError("sprintf: the format ends in the middle of a verb")
+		}
+
+		spec, verb := format[start:i+1], format[i]
+
+		if verb == '%' {
+			result.WriteByte('%')
+			continue
+		}
+
+		if next >= len(args) {
+			return "", newError("sprintf: missing argument for " + spec)
+		}
+
+		value, err := formatArgument(verb, args[next])
+		if err != nil {
+			return "", err
+		}
+		next++
+
+		result.WriteString(fmt.Sprintf(spec, value))
+	}
+
+	if next < len(args) {
+		return "", newError(fmt.Sprintf("sprintf: %d arguments left without a verb", len(args)-next))
+	}
+
+	return result.String(), nil
+}
+
+// formatArgument converts a Monkey object into the Go value expected by the
+// verb.
+func formatArgument(verb byte, arg object.Object) (interface{}, *object.Error) {
+	switch verb {
+	case 'd':
+		if integer, ok := arg.(*object.Integer); ok {
+			return integer.Value, nil
+		}
+	case 'f', 'e', 'g':
+		if isNumber(arg) {
+			return toFloat(arg), nil
+		}
+	case 'x', 'X':
+		switch arg := arg.(type) {
+		case *object.Integer:
+			return arg.Value, nil
+		case *object.String:
+			return arg.Value, nil
+		}
+	case 't':
+		if boolean, ok := arg.(*object.Boolean); ok {
+			return boolean.Value, nil
+		}
+	case 's', 'v', 'q':
+		return arg.Inspect(), nil
+	default:
+		return nil, newError(fmt.Sprintf("sprintf: unknown verb %%%c", verb))
+	}
+
+	return nil, newError(fmt.Sprintf("sprintf: %%%c can't format %s", verb, arg.Type()))
+}
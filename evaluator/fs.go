@@ -0,0 +1,235 @@
+package evaluator
+
+import (
+	"io/ioutil"
+	"monkey/object"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// FSPermissions limits what the fs module may touch. With no AllowedRoots
+// every path is reachable; otherwise paths must be inside one of them.
+type FSPermissions struct {
+	AllowedRoots []string
+	ReadOnly     bool
+}
+
+func init() {
+	registerNativeModule("fs", map[string]object.Object{
+		"read_file":   &object.Builtin{InterpreterFn: b_fs_read_file},
+		"write_file":  &object.Builtin{InterpreterFn: b_fs_write_file},
+		"append_file": &object.Builtin{InterpreterFn: b_fs_append_file},
+		"exists":      &object.Builtin{InterpreterFn: b_fs_exists},
+		"list_dir":    &object.Builtin{InterpreterFn: b_fs_list_dir},
+		"mkdir":       &object.Builtin{InterpreterFn: b_fs_mkdir},
+		"remove":      &object.Builtin{InterpreterFn: b_fs_remove},
+		"stat":        &object.Builtin{InterpreterFn: b_fs_stat},
+	})
+}
+
+// IsRestricted reports whether perm limits the filesystem in any way.
+func (perm FSPermissions) IsRestricted() bool {
+	return perm.ReadOnly || len(perm.AllowedRoots) > 0
+}
+
+// checkPath returns the path the fs module should use for the Monkey string
+// path, or an error if the permissions don't allow the access.
+func (perm FSPermissions) checkPath(name string, path string, write bool) (string, *object.Error) {
+	if write && perm.ReadOnly {
+		return "", newError(name + ": the filesystem is read only")
+	}
+
+	if len(perm.AllowedRoots) == 0 {
+		return path, nil
+	}
+
+	resolved := resolvePath(path)
+
+	for _, root := range perm.AllowedRoots {
+		rel, err := filepath.Rel(resolvePath(root), resolved)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return path, nil
+		}
+	}
+
+	return "", newError(name + ": access to " + path + " is not allowed")
+}
+
+// resolvePath makes path absolute and follows symlinks, so links can't be
+// used to escape the allowed roots. Paths that don't exist yet are resolved
+// through their closest existing parent.
+func resolvePath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+
+	missing := []string{}
+	for {
+		if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+			return filepath.Join(append([]string{resolved}, missing...)...)
+		}
+
+		parent := filepath.Dir(abs)
+		if parent == abs {
+			return filepath.Join(append([]string{abs}, missing...)...)
+		}
+
+		missing = append([]string{filepath.Base(abs)}, missing...)
+		abs = parent
+	}
+}
+
+func fsError(name string, err error) *object.Error {
+	return newError(name + ": " + err.Error())
+}
+
+func b_fs_read_file(interp object.Interpreter, args ...object.Object) object.Object {
+	if err := checkArgs("fs.read_file", args, object.STRING_OBJ); err != nil {
+		return err
+	}
+
+	path, err := interp.(*Interpreter).Filesystem.checkPath("fs.read_file", args[0].(*object.String).Value, false)
+	if err != nil {
+		return err
+	}
+
+	content, readErr := ioutil.ReadFile(path)
+	if readErr != nil {
+		return fsError("fs.read_file", readErr)
+	}
+
+	return &object.String{Value: string(content)}
+}
+
+func b_fs_write_file(interp object.Interpreter, args ...object.Object) object.Object {
+	return writeFile(interp, "fs.write_file", args, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+}
+
+func b_fs_append_file(interp object.Interpreter, args ...object.Object) object.Object {
+	return writeFile(interp, "fs.append_file", args, os.O_WRONLY|os.O_CREATE|os.O_APPEND)
+}
+
+func writeFile(interp object.Interpreter, name string, args []object.Object, flag int) object.Object {
+	if err := checkArgs(name, args, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+		return err
+	}
+
+	path, err := interp.(*Interpreter).Filesystem.checkPath(name, args[0].(*object.String).Value, true)
+	if err != nil {
+		return err
+	}
+
+	file, openErr := os.OpenFile(path, flag, 0644)
+	if openErr != nil {
+		return fsError(name, openErr)
+	}
+	defer file.Close()
+
+	if _, writeErr := file.WriteString(args[1].(*object.String).Value); writeErr != nil {
+		return fsError(name, writeErr)
+	}
+
+	return NULL
+}
+
+func b_fs_exists(interp object.Interpreter, args ...object.Object) object.Object {
+	if err := checkArgs("fs.exists", args, object.STRING_OBJ); err != nil {
+		return err
+	}
+
+	path, err := interp.(*Interpreter).Filesystem.checkPath("fs.exists", args[0].(*object.String).Value, false)
+	if err != nil {
+		return err
+	}
+
+	_, statErr := os.Stat(path)
+
+	return nativeBoolToBooleanObject(statErr == nil)
+}
+
+func b_fs_list_dir(interp object.Interpreter, args ...object.Object) object.Object {
+	if err := checkArgs("fs.list_dir", args, object.STRING_OBJ); err != nil {
+		return err
+	}
+
+	path, err := interp.(*Interpreter).Filesystem.checkPath("fs.list_dir", args[0].(*object.String).Value, false)
+	if err != nil {
+		return err
+	}
+
+	entries, readErr := ioutil.ReadDir(path)
+	if readErr != nil {
+		return fsError("fs.list_dir", readErr)
+	}
+
+	names := make([]string, len(entries))
+	for i, entry := range entries {
+		names[i] = entry.Name()
+	}
+	sort.Strings(names)
+
+	return stringArray(names)
+}
+
+// b_fs_mkdir creates a directory along with any missing parents.
+func b_fs_mkdir(interp object.Interpreter, args ...object.Object) object.Object {
+	if err := checkArgs("fs.mkdir", args, object.STRING_OBJ); err != nil {
+		return err
+	}
+
+	path, err := interp.(*Interpreter).Filesystem.checkPath("fs.mkdir", args[0].(*object.String).Value, true)
+	if err != nil {
+		return err
+	}
+
+	if mkdirErr := os.MkdirAll(path, 0755); mkdirErr != nil {
+		return fsError("fs.mkdir", mkdirErr)
+	}
+
+	return NULL
+}
+
+// b_fs_remove deletes a file or an empty directory.
+func b_fs_remove(interp object.Interpreter, args ...object.Object) object.Object {
+	if err := checkArgs("fs.remove", args, object.STRING_OBJ); err != nil {
+		return err
+	}
+
+	path, err := interp.(*Interpreter).Filesystem.checkPath("fs.remove", args[0].(*object.String).Value, true)
+	if err != nil {
+		return err
+	}
+
+	if removeErr := os.Remove(path); removeErr != nil {
+		return fsError("fs.remove", removeErr)
+	}
+
+	return NULL
+}
+
+func b_fs_stat(interp object.Interpreter, args ...object.Object) object.Object {
+	if err := checkArgs("fs.stat", args, object.STRING_OBJ); err != nil {
+		return err
+	}
+
+	path, err := interp.(*Interpreter).Filesystem.checkPath("fs.stat", args[0].(*object.String).Value, false)
+	if err != nil {
+		return err
+	}
+
+	info, statErr := os.Stat(path)
+	if statErr != nil {
+		return fsError("fs.stat", statErr)
+	}
+
+	return newStringHash(map[string]object.Object{
+		"name":     &object.String{Value: info.Name()},
+		"size":     &object.Integer{Value: info.Size()},
+		"is_dir":   nativeBoolToBooleanObject(info.IsDir()),
+		"mode":     &object.String{Value: info.Mode().String()},
+		"modified": &object.Integer{Value: info.ModTime().Unix()},
+	})
+}
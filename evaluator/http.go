@@ -0,0 +1,210 @@
+package evaluator
+
+import (
+	"fmt"
+	"io/ioutil"
+	"monkey/object"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+func init() {
+	registerNativeModule("http", map[string]object.Object{
+		"get":     &object.Builtin{InterpreterFn: b_http_get},
+		"post":    &object.Builtin{InterpreterFn: b_http_post},
+		"request": &object.Builtin{InterpreterFn: b_http_request},
+		"serve":   &object.Builtin{InterpreterFn: b_http_serve},
+	})
+}
+
+func b_http_get(interp object.Interpreter, args ...object.Object) object.Object {
+	if err := checkOptionalArgs("http.get", args, 1, object.STRING_OBJ, object.HASH_OBJ); err != nil {
+		return err
+	}
+
+	request := []object.Object{&object.String{Value: "GET"}, args[0], &object.String{Value: ""}}
+	return b_http_request(interp, append(request, args[1:]...)...)
+}
+
+func b_http_post(interp object.Interpreter, args ...object.Object) object.Object {
+	if err := checkOptionalArgs("http.post", args, 2, object.STRING_OBJ, object.STRING_OBJ, object.HASH_OBJ); err != nil {
+		return err
+	}
+
+	return b_http_request(interp, append([]object.Object{&object.String{Value: "POST"}}, args...)...)
+}
+
+// b_http_request sends a request and returns a hash with the status code,
+// the headers and the body of the response.
+func b_http_request(interp object.Interpreter, args ...object.Object) object.Object {
+	if err := checkOptionalArgs("http.request", args, 2, object.STRING_OBJ, object.STRING_OBJ, object.STRING_OBJ, object.HASH_OBJ); err != nil {
+		return err
+	}
+
+	method, url := args[0].(*object.String).Value, args[1].(*object.String).Value
+
+	body := ""
+	if len(args) > 2 {
+		body = args[2].(*object.String).Value
+	}
+
+	req, err := http.NewRequestWithContext(interp.(*Interpreter).Context, method, url, strings.NewReader(body))
+	if err != nil {
+		return newError("http.request: " + err.Error())
+	}
+
+	if len(args) > 3 {
+		for _, pair := range args[3].(*object.Hash).Pairs {
+			value, ok := pair.Value.(*object.String)
+			if !ok {
+				return newError("http.request: header values must be STRING, got " + pair.Value.Type())
+			}
+			req.Header.Set(pair.Key.Inspect(), value.Value)
+		}
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return newError("http.request: " + err.Error())
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return newError("http.request: " + err.Error())
+	}
+
+	return newStringHash(map[string]object.Object{
+		"status":  &object.Integer{Value: int64(resp.StatusCode)},
+		"headers": headersToHash(resp.Header),
+		"body":    &object.String{Value: string(respBody)},
+	})
+}
+
+func headersToHash(header http.Header) *object.Hash {
+	pairs := make(map[string]object.Object, len(header))
+	for name, values := range header {
+		pairs[name] = &object.String{Value: strings.Join(values, ", ")}
+	}
+	return newStringHash(pairs)
+}
+
+// b_http_serve listens on addr and calls handler with a hash describing each
+// request. The handler returns either the body of the response or a hash with
+// its status, headers and body. Calls to the handler never run concurrently,
+// since the evaluator isn't safe for concurrent use. It blocks until the
+// server fails or the interpreter's context is cancelled.
+func b_http_serve(interp object.Interpreter, args ...object.Object) object.Object {
+	if err := checkArgs("http.serve", args, object.STRING_OBJ, ""); err != nil {
+		return err
+	}
+
+	handler := args[1]
+	if handler.Type() != object.FUNCTION_OBJ && handler.Type() != object.BUILTIN_OBJ {
+		return newError("http.serve: the handler must be a function, got " + handler.Type())
+	}
+
+	server := &http.Server{Addr: args[0].(*object.String).Value, Handler: newHTTPHandler(interp, handler)}
+	ctx := interp.(*Interpreter).Context
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			server.Close()
+		case <-done:
+		}
+	}()
+
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return newError("http.serve: " + err.Error())
+	}
+
+	if err := ctx.Err(); err != nil {
+		return newError("http.serve: interrupted: " + err.Error())
+	}
+
+	return NULL
+}
+
+func newHTTPHandler(interp object.Interpreter, handler object.Object) http.Handler {
+	var mutex sync.Mutex
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		query := make(map[string]object.Object)
+		for name, values := range r.URL.Query() {
+			query[name] = &object.String{Value: strings.Join(values, ", ")}
+		}
+
+		request := newStringHash(map[string]object.Object{
+			"method":  &object.String{Value: r.Method},
+			"path":    &object.String{Value: r.URL.Path},
+			"query":   newStringHash(query),
+			"headers": headersToHash(r.Header),
+			"body":    &object.String{Value: string(body)},
+		})
+
+		writeHTTPResponse(w, callHandler(&mutex, interp, handler, request))
+	})
+}
+
+// callHandler calls the handler while holding mutex. The unlock is deferred so
+// that a handler that panics doesn't block every later request.
+func callHandler(mutex *sync.Mutex, interp object.Interpreter, handler, request object.Object) object.Object {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	return interp.Call(handler, request)
+}
+
+func writeHTTPResponse(w http.ResponseWriter, response object.Object) {
+	switch response := response.(type) {
+	case *object.String:
+		fmt.Fprint(w, response.Value)
+	case *object.Hash:
+		status := http.StatusOK
+		body := ""
+
+		for _, pair := range response.SortedPairs() {
+			switch pair.Key.Inspect() {
+			case "status":
+				if code, ok := pair.Value.(*object.Integer); ok {
+					// WriteHeader panics on codes that don't have three digits.
+					if code.Value < 100 || code.Value > 999 {
+						message := fmt.Sprintf("http.serve: invalid status code %d", code.Value)
+						fmt.Fprintln(os.Stderr, message)
+						http.Error(w, message, http.StatusInternalServerError)
+						return
+					}
+					status = int(code.Value)
+				}
+			case "body":
+				body = pair.Value.Inspect()
+			case "headers":
+				if headers, ok := pair.Value.(*object.Hash); ok {
+					for _, header := range headers.Pairs {
+						w.Header().Set(header.Key.Inspect(), header.Value.Inspect())
+					}
+				}
+			}
+		}
+
+		w.WriteHeader(status)
+		fmt.Fprint(w, body)
+	case *object.Error:
+		fmt.Fprintln(os.Stderr, "http.serve: "+response.Inspect())
+		http.Error(w, response.Message, http.StatusInternalServerError)
+	default:
+		fmt.Fprint(w, response.Inspect())
+	}
+}
@@ -0,0 +1,85 @@
+package evaluator
+
+import (
+	"io/ioutil"
+	"log"
+	"monkey/object"
+	"net/http/httptest"
+	"testing"
+)
+
+func hashValue(t *testing.T, obj object.Object, key string) object.Object {
+	t.Helper()
+
+	hash, ok := obj.(*object.Hash)
+	if !ok {
+		t.Fatalf("expected a hash, got %T (%s)", obj, inspect(obj))
+	}
+
+	value, ok := hash.Get(&object.String{Value: key})
+	if !ok {
+		t.Fatalf("expected %s to have a %q key", hash.Inspect(), key)
+	}
+
+	return value
+}
+
+func testString(t *testing.T, obj object.Object, expected string) {
+	t.Helper()
+
+	str, ok := obj.(*object.String)
+	if !ok {
+		t.Fatalf("expected a string, got %T (%s)", obj, inspect(obj))
+	}
+
+	if str.Value != expected {
+		t.Errorf("expected %q, got %q", expected, str.Value)
+	}
+}
+
+func TestHTTPHandler(t *testing.T) {
+	handler := testEval(t, `fn(req) {
+		if (req.path == "/fail") {
+			return 1 + "a"
+		}
+		if (req.path == "/status") {
+			return {"status": 42}
+		}
+		if (req.path == "/panic") {
+			if (true) { "a" < "b" }
+		}
+		if (req.method == "GET") {
+			return "hello " + req.query.name
+		}
+		{"status": 201, "headers": {"X-Method": req.method}, "body": req.path + ": " + req.body}
+	}`)
+
+	in := NewInterpreter()
+
+	server := httptest.NewUnstartedServer(newHTTPHandler(in, handler))
+	server.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	server.Start()
+	defer server.Close()
+
+	response := b_http_post(in, &object.String{Value: server.URL + "/items"}, &object.String{Value: "an item"})
+	testInteger(t, hashValue(t, response, "status"), 201)
+	testString(t, hashValue(t, response, "body"), "/items: an item")
+	testString(t, hashValue(t, hashValue(t, response, "headers"), "X-Method"), "POST")
+
+	response = b_http_get(in, &object.String{Value: server.URL + "/greet?name=monkey"})
+	testInteger(t, hashValue(t, response, "status"), 200)
+	testString(t, hashValue(t, response, "body"), "hello monkey")
+
+	response = b_http_get(in, &object.String{Value: server.URL + "/fail"})
+	testInteger(t, hashValue(t, response, "status"), 500)
+
+	response = b_http_get(in, &object.String{Value: server.URL + "/status"})
+	testInteger(t, hashValue(t, response, "status"), 500)
+	testString(t, hashValue(t, response, "body"), "http.serve: invalid status code 42\n")
+
+	// A handler that panics must not keep later requests waiting on the lock.
+	b_http_get(in, &object.String{Value: server.URL + "/panic"})
+
+	response = b_http_get(in, &object.String{Value: server.URL + "/greet?name=again"})
+	testString(t, hashValue(t, response, "body"), "hello again")
+}
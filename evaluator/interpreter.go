@@ -0,0 +1,75 @@
+package evaluator
+
+import (
+	"bufio"
+	"context"
+	"math/rand"
+	"monkey/object"
+	"os"
+	"time"
+)
+
+// Interpreter holds the state shared by the scripts one interpreter runs: its
+// module cache and the settings below. It is set on the root environment with
+// SetInterpreter, and every scope and module evaluated from there uses it.
+// Embedders running several interpreters give each its own, so that they
+// neither share modules nor each other's settings.
+type Interpreter struct {
+	Modules *ModuleLoader
+
+	// Filesystem is checked by every function of the fs and csv modules that
+	// touches a file.
+	Filesystem FSPermissions
+
+	// AllowSubprocesses lets embedders forbid os.run. Programs started by
+	// os.run aren't bound by Filesystem, so os.run is also refused whenever
+	// the filesystem is restricted.
+	AllowSubprocesses bool
+
+	// Context lets embedders cancel a running script. Loops, function calls
+	// and sleeping builtins stop with an error once it is done.
+	Context context.Context
+
+	// Args is the value of os.args, the arguments given to the script.
+	Args []string
+
+	// Random is the source of every function of the random module. Embedders
+	// can replace it with a seeded generator to get reproducible runs.
+	Random *rand.Rand
+
+	// Stdin is read by read. It is shared by every call, and by the REPL, so
+	// that input buffered by one reader isn't lost to the others.
+	Stdin *bufio.Scanner
+}
+
+// NewInterpreter returns an interpreter that looks for modules in the
+// DefaultSearchPath and lets scripts use the whole filesystem and start
+// programs until its settings are changed.
+func NewInterpreter() *Interpreter {
+	return &Interpreter{
+		Modules:           NewModuleLoader(DefaultSearchPath()),
+		AllowSubprocesses: true,
+		Context:           context.Background(),
+		Args:              []string{},
+		Random:            rand.New(rand.NewSource(time.Now().UnixNano())),
+		Stdin:             bufio.NewScanner(os.Stdin),
+	}
+}
+
+// Call calls fn with args, which is how builtins call the functions they are
+// given.
+func (in *Interpreter) Call(fn object.Object, args ...object.Object) object.Object {
+	return callFunction(in, fn, args, nil)
+}
+
+// interpreterOf returns the interpreter env runs in. An environment without
+// one gets a new interpreter with the default settings.
+func interpreterOf(env *object.Environment) *Interpreter {
+	if in, ok := env.Interpreter().(*Interpreter); ok {
+		return in
+	}
+
+	in := NewInterpreter()
+	env.SetInterpreter(in)
+	return in
+}
@@ -0,0 +1,59 @@
+package evaluator
+
+import (
+	"bufio"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInterpreterFilesystem(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.txt")
+	input := `use fs; fs.write_file("` + filepath.ToSlash(path) + `", "hi"); fs.read_file("` + filepath.ToSlash(path) + `")`
+
+	readOnly := NewInterpreter()
+	readOnly.Filesystem = FSPermissions{ReadOnly: true}
+
+	_, result := evalIn(t, input, readOnly)
+	testError(t, result, "fs.write_file: the filesystem is read only")
+
+	// The permissions of one interpreter don't leak into another one.
+	_, result = evalIn(t, input, NewInterpreter())
+	testString(t, result, "hi")
+}
+
+func TestInterpreterOS(t *testing.T) {
+	withArgs := NewInterpreter()
+	withArgs.Args = []string{"a", "b"}
+
+	_, result := evalIn(t, `use os; len(os.args)`, withArgs)
+	testInteger(t, result, 2)
+
+	_, result = evalIn(t, `use os; len(os.args)`, NewInterpreter())
+	testInteger(t, result, 0)
+
+	noRun := NewInterpreter()
+	noRun.AllowSubprocesses = false
+
+	_, result = evalIn(t, `use os; os.run("true")`, noRun)
+	testError(t, result, "os.run: starting programs is not allowed")
+
+	restricted := NewInterpreter()
+	restricted.Filesystem = FSPermissions{AllowedRoots: []string{t.TempDir()}}
+
+	_, result = evalIn(t, `use os; os.run("true")`, restricted)
+	testError(t, result, "os.run: starting programs is not allowed while the filesystem is restricted")
+}
+
+func TestInterpreterStdin(t *testing.T) {
+	in := NewInterpreter()
+	in.Stdin = bufio.NewScanner(strings.NewReader("first\nsecond\n"))
+
+	// Both calls, and anything else reading in.Stdin, share its buffer.
+	_, result := evalIn(t, `read(">") + " " + read(">") + " [" + read(">") + "]"`, in)
+	testString(t, result, "first second []")
+
+	if in.Stdin.Scan() {
+		t.Errorf("expected read to have consumed the input, got %q left", in.Stdin.Text())
+	}
+}
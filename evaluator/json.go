@@ -0,0 +1,177 @@
+package evaluator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"monkey/object"
+	"strings"
+	"unicode/utf8"
+)
+
+func init() {
+	registerNativeModule("json", map[string]object.Object{
+		"parse":     &object.Builtin{Fn: b_json_parse},
+		"stringify": &object.Builtin{Fn: b_json_stringify},
+	})
+}
+
+func b_json_parse(args ...object.Object) object.Object {
+	if err := checkArgs("json.parse", args, object.STRING_OBJ); err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(args[0].(*object.String).Value))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return newError("json.parse: " + err.Error())
+	}
+
+	if decoder.More() {
+		return newError("json.parse: unexpected data after the top level value")
+	}
+
+	return fromJSON(value)
+}
+
+func fromJSON(value interface{}) object.Object {
+	switch value := value.(type) {
+	case nil:
+		return NULL
+	case bool:
+		return nativeBoolToBooleanObject(value)
+	case string:
+		return &object.String{Value: value}
+	case json.Number:
+		if integer, err := value.Int64(); err == nil {
+			return &object.Integer{Value: integer}
+		}
+		float, _ := value.Float64()
+		return &object.Float{Value: float}
+	case []interface{}:
+		elements := make([]object.Object, len(value))
+		for i, element := range value {
+			elements[i] = fromJSON(element)
+		}
+		return &object.Array{Elements: elements}
+	case map[string]interface{}:
+		pairs := make(map[string]object.Object, len(value))
+		for k, v := range value {
+			pairs[k] = fromJSON(v)
+		}
+		return newStringHash(pairs)
+	}
+
+	return NULL
+}
+
+// maxJSONIndent bounds the indent of json.stringify, which is repeated once
+// per nesting level on every line.
+const maxJSONIndent = 10
+
+// b_json_stringify encodes a value as JSON. The optional indent is either the
+// number of spaces or the string used for each level of indentation.
+func b_json_stringify(args ...object.Object) object.Object {
+	if err := checkOptionalArgs("json.stringify", args, 1, "", ""); err != nil {
+		return err
+	}
+
+	indent := ""
+	if len(args) == 2 {
+		switch arg := args[1].(type) {
+		case *object.Integer:
+			if arg.Value < 0 || arg.Value > maxJSONIndent {
+				return newError(fmt.Sprintf("json.stringify: the indent must be between 0 and %d, got %d", maxJSONIndent, arg.Value))
+			}
+			indent = strings.Repeat(" ", int(arg.Value))
+		case *object.String:
+			if utf8.RuneCountInString(arg.Value) > maxJSONIndent {
+				return newError(fmt.Sprintf("json.stringify: the indent must not be longer than %d characters", maxJSONIndent))
+			}
+			indent = arg.Value
+		default:
+			return newError("json.stringify: the indent must be an INTEGER or a STRING, got " + arg.Type())
+		}
+	}
+
+	value, err := toJSON(args[0], map[object.Object]bool{})
+	if err != nil {
+		return err
+	}
+
+	var result bytes.Buffer
+
+	encoder := json.NewEncoder(&result)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", indent)
+
+	if err := encoder.Encode(value); err != nil {
+		return newError("json.stringify: " + err.Error())
+	}
+
+	return &object.String{Value: strings.TrimSuffix(result.String(), "\n")}
+}
+
+// toJSON converts obj into the values encoding/json knows about. visiting
+// holds the arrays and hashes that contain obj, to detect cycles.
+func toJSON(obj object.Object, visiting map[object.Object]bool) (interface{}, *object.Error) {
+	switch obj := obj.(type) {
+	case *object.Null:
+		return nil, nil
+	case *object.Boolean:
+		return obj.Value, nil
+	case *object.Integer:
+		return obj.Value, nil
+	case *object.Float:
+		if math.IsNaN(obj.Value) || math.IsInf(obj.Value, 0) {
+			return nil, newError("json.stringify: cannot encode " + obj.Inspect())
+		}
+		return obj.Value, nil
+	case *object.String:
+		return obj.Value, nil
+	case *object.Array:
+		if visiting[obj] {
+			return nil, newError("json.stringify: cannot encode a cyclic structure")
+		}
+		visiting[obj] = true
+		defer delete(visiting, obj)
+
+		elements := make([]interface{}, len(obj.Elements))
+		for i, element := range obj.Elements {
+			value, err := toJSON(element, visiting)
+			if err != nil {
+				return nil, err
+			}
+			elements[i] = value
+		}
+		return elements, nil
+	case *object.Hash:
+		if visiting[obj] {
+			return nil, newError("json.stringify: cannot encode a cyclic structure")
+		}
+		visiting[obj] = true
+		defer delete(visiting, obj)
+
+		pairs := make(map[string]interface{}, len(obj.Pairs))
+		for _, pair := range obj.Pairs {
+			// JSON keys are strings, so 1 and "1" would silently overwrite
+			// each other.
+			key := pair.Key.Inspect()
+			if _, ok := pairs[key]; ok {
+				return nil, newError(fmt.Sprintf("json.stringify: more than one key encodes as %q", key))
+			}
+
+			value, err := toJSON(pair.Value, visiting)
+			if err != nil {
+				return nil, err
+			}
+			pairs[key] = value
+		}
+		return pairs, nil
+	default:
+		return nil, newError("json.stringify: values of type " + obj.Type() + " can't be encoded")
+	}
+}
@@ -0,0 +1,32 @@
+package evaluator
+
+import "testing"
+
+func TestJSONStringify(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`use json; json.stringify({1: "a", "b": [true, null]})`, `{"1":"a","b":[true,null]}`},
+		{`use json; json.stringify([1], 2)`, "[\n  1\n]"},
+		{`use json; json.stringify([1], "--")`, "[\n--1\n]"},
+	}
+
+	for _, tt := range tests {
+		testString(t, testEval(t, tt.input), tt.expected)
+	}
+
+	errors := []struct {
+		input    string
+		expected string
+	}{
+		{`use json; json.stringify({1: "a", "1": "b"})`, `json.stringify: more than one key encodes as "1"`},
+		{`use json; json.stringify([1], 11)`, "json.stringify: the indent must be between 0 and 10, got 11"},
+		{`use json; json.stringify([1], "           ")`, "json.stringify: the indent must not be longer than 10 characters"},
+		{`use json; let a = [1]; push(a, a); json.stringify(a)`, "json.stringify: cannot encode a cyclic structure"},
+	}
+
+	for _, tt := range errors {
+		testError(t, testEval(t, tt.input), tt.expected)
+	}
+}
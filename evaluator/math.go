@@ -0,0 +1,270 @@
+package evaluator
+
+import (
+	"fmt"
+	"math"
+	"monkey/object"
+)
+
+// The math module keeps integers as integers whenever the result is exact
+// (abs, min, max, clamp, gcd and pow with a non negative exponent), rounds to
+// integers in floor and ceil, and returns floats everywhere else.
+func init() {
+	registerNativeModule("math", map[string]object.Object{
+		"abs":   &object.Builtin{Fn: b_math_abs},
+		"min":   &object.Builtin{Fn: b_math_min},
+		"max":   &object.Builtin{Fn: b_math_max},
+		"pow":   &object.Builtin{Fn: b_math_pow},
+		"sqrt":  &object.Builtin{Fn: b_math_sqrt},
+		"floor": &object.Builtin{Fn: b_math_floor},
+		"ceil":  &object.Builtin{Fn: b_math_ceil},
+		"clamp": &object.Builtin{Fn: b_math_clamp},
+		"gcd":   &object.Builtin{Fn: b_math_gcd},
+		"sin":   &object.Builtin{Fn: b_math_sin},
+		"cos":   &object.Builtin{Fn: b_math_cos},
+		"log":   &object.Builtin{Fn: b_math_log},
+		"pi":    &object.Float{Value: math.Pi},
+		"e":     &object.Float{Value: math.E},
+	})
+}
+
+func checkNumberArgs(name string, args []object.Object, count int) *object.Error {
+	if len(args) != count {
+		return newError(fmt.Sprintf("%s: invalid number of arguments, want %d, got %d", name, count, len(args)))
+	}
+
+	for i, arg := range args {
+		if !isNumber(arg) {
+			return newError(fmt.Sprintf("%s: argument %d must be a number, got %s", name, i+1, arg.Type()))
+		}
+	}
+
+	return nil
+}
+
+func b_math_abs(args ...object.Object) object.Object {
+	if err := checkNumberArgs("math.abs", args, 1); err != nil {
+		return err
+	}
+
+	// -MinInt64 doesn't fit in an int64, so like pow it falls back to a float.
+	if integer, ok := args[0].(*object.Integer); ok && integer.Value != math.MinInt64 {
+		if integer.Value < 0 {
+			return &object.Integer{Value: -integer.Value}
+		}
+		return integer
+	}
+
+	return &object.Float{Value: math.Abs(toFloat(args[0]))}
+}
+
+func b_math_min(args ...object.Object) object.Object {
+	return extreme("math.min", args, func(a, b float64) bool { return a < b })
+}
+
+func b_math_max(args ...object.Object) object.Object {
+	return extreme("math.max", args, func(a, b float64) bool { return a > b })
+}
+
+// extreme returns the argument that beats every other one according to
+// better. It takes either several numbers or a single array of numbers.
+func extreme(name string, args []object.Object, better func(a, b float64) bool) object.Object {
+	if len(args) == 1 {
+		if arr, ok := args[0].(*object.Array); ok {
+			args = arr.Elements
+		}
+	}
+
+	if len(args) == 0 {
+		return newError(name + ": want at least one number")
+	}
+
+	if err := checkNumberArgs(name, args, len(args)); err != nil {
+		return err
+	}
+
+	result := args[0]
+	for _, arg := range args[1:] {
+		if better(toFloat(arg), toFloat(result)) {
+			result = arg
+		}
+	}
+
+	return result
+}
+
+func b_math_pow(args ...object.Object) object.Object {
+	if err := checkNumberArgs("math.pow", args, 2); err != nil {
+		return err
+	}
+
+	base, baseIsInt := args[0].(*object.Integer)
+	exponent, exponentIsInt := args[1].(*object.Integer)
+
+	if baseIsInt && exponentIsInt && exponent.Value >= 0 {
+		if result, ok := intPow(base.Value, exponent.Value); ok {
+			return &object.Integer{Value: result}
+		}
+	}
+
+	return &object.Float{Value: math.Pow(toFloat(args[0]), toFloat(args[1]))}
+}
+
+// intPow computes base to the power of exponent by squaring. It returns false
+// if the result doesn't fit in an int64, in which case pow falls back to a
+// float.
+func intPow(base, exponent int64) (int64, bool) {
+	result := int64(1)
+
+	for exponent > 0 {
+		var ok bool
+
+		if exponent&1 == 1 {
+			if result, ok = mulInt64(result, base); !ok {
+				return 0, false
+			}
+		}
+
+		exponent >>= 1
+		if exponent > 0 {
+			if base, ok = mulInt64(base, base); !ok {
+				return 0, false
+			}
+		}
+	}
+
+	return result, true
+}
+
+func mulInt64(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+
+	result := a * b
+	if result/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, false
+	}
+
+	return result, true
+}
+
+func b_math_sqrt(args ...object.Object) object.Object {
+	if err := checkNumberArgs("math.sqrt", args, 1); err != nil {
+		return err
+	}
+
+	value := toFloat(args[0])
+	if value < 0 {
+		return newError("math.sqrt: argument must not be negative")
+	}
+
+	return &object.Float{Value: math.Sqrt(value)}
+}
+
+func b_math_floor(args ...object.Object) object.Object {
+	if err := checkNumberArgs("math.floor", args, 1); err != nil {
+		return err
+	}
+
+	return roundToInteger("math.floor", args[0], math.Floor)
+}
+
+func b_math_ceil(args ...object.Object) object.Object {
+	if err := checkNumberArgs("math.ceil", args, 1); err != nil {
+		return err
+	}
+
+	return roundToInteger("math.ceil", args[0], math.Ceil)
+}
+
+// roundToInteger rounds a float with round and converts it to an integer.
+// Integers are returned unchanged, since going through a float64 would lose
+// precision above 2^53.
+func roundToInteger(name string, number object.Object, round func(float64) float64) object.Object {
+	if integer, ok := number.(*object.Integer); ok {
+		return integer
+	}
+
+	value := round(toFloat(number))
+	if !(value >= math.MinInt64 && value < -math.MinInt64) {
+		return newError(fmt.Sprintf("%s: %s doesn't fit in an integer", name, number.Inspect()))
+	}
+
+	return &object.Integer{Value: int64(value)}
+}
+
+func b_math_clamp(args ...object.Object) object.Object {
+	if err := checkNumberArgs("math.clamp", args, 3); err != nil {
+		return err
+	}
+
+	value, low, high := toFloat(args[0]), toFloat(args[1]), toFloat(args[2])
+	if low > high {
+		return newError("math.clamp: the lower bound is greater than the upper bound")
+	}
+
+	if value < low {
+		return args[1]
+	} else if value > high {
+		return args[2]
+	}
+	return args[0]
+}
+
+func b_math_gcd(args ...object.Object) object.Object {
+	if err := checkArgs("math.gcd", args, object.INTEGER_OBJ, object.INTEGER_OBJ); err != nil {
+		return err
+	}
+
+	a, b := args[0].(*object.Integer).Value, args[1].(*object.Integer).Value
+	for b != 0 {
+		a, b = b, a%b
+	}
+
+	if a < 0 {
+		a = -a
+	}
+
+	return &object.Integer{Value: a}
+}
+
+func b_math_sin(args ...object.Object) object.Object {
+	if err := checkNumberArgs("math.sin", args, 1); err != nil {
+		return err
+	}
+
+	return &object.Float{Value: math.Sin(toFloat(args[0]))}
+}
+
+func b_math_cos(args ...object.Object) object.Object {
+	if err := checkNumberArgs("math.cos", args, 1); err != nil {
+		return err
+	}
+
+	return &object.Float{Value: math.Cos(toFloat(args[0]))}
+}
+
+// b_math_log returns the natural logarithm, or the logarithm in the given
+// base.
+func b_math_log(args ...object.Object) object.Object {
+	if len(args) == 2 {
+		if err := checkNumberArgs("math.log", args, 2); err != nil {
+			return err
+		}
+		if toFloat(args[0]) <= 0 || toFloat(args[1]) <= 0 || toFloat(args[1]) == 1 {
+			return newError("math.log: arguments out of range")
+		}
+		return &object.Float{Value: math.Log(toFloat(args[0])) / math.Log(toFloat(args[1]))}
+	}
+
+	if err := checkNumberArgs("math.log", args, 1); err != nil {
+		return err
+	}
+
+	if toFloat(args[0]) <= 0 {
+		return newError("math.log: argument must be positive")
+	}
+
+	return &object.Float{Value: math.Log(toFloat(args[0]))}
+}
@@ -0,0 +1,68 @@
+package evaluator
+
+import (
+	"math"
+	"monkey/object"
+	"testing"
+)
+
+func TestIntPow(t *testing.T) {
+	tests := []struct {
+		base, exponent int64
+		expected       int64
+		ok             bool
+	}{
+		{2, 10, 1024, true},
+		{3, 39, 4052555153018976267, true},
+		{-2, 63, math.MinInt64, true},
+		{2, 63, 0, false},
+		{3, 64, 0, false},
+		{1, math.MaxInt64, 1, true},
+		{-1, math.MaxInt64, -1, true},
+		{0, 0, 1, true},
+		{0, math.MaxInt64, 0, true},
+	}
+
+	for _, tt := range tests {
+		result, ok := intPow(tt.base, tt.exponent)
+		if ok != tt.ok || result != tt.expected {
+			t.Errorf("intPow(%d, %d) = %d, %t; want %d, %t", tt.base, tt.exponent, result, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+func TestRoundingAndAbs(t *testing.T) {
+	integers := []struct {
+		input    string
+		expected int64
+	}{
+		{`use math; math.floor(9007199254740993)`, 9007199254740993},
+		{`use math; math.ceil(-9007199254740993)`, -9007199254740993},
+		{`use math; math.floor(-2.5)`, -3},
+		{`use math; math.ceil(2.1)`, 3},
+		{`use math; math.abs(-9223372036854775807)`, math.MaxInt64},
+	}
+
+	for _, tt := range integers {
+		testInteger(t, testEval(t, tt.input), tt.expected)
+	}
+
+	errors := []struct {
+		input    string
+		expected string
+	}{
+		{`use math; math.floor(math.pow(10.0, 400))`, "math.floor: +Inf doesn't fit in an integer"},
+		{`use math; math.ceil(-math.pow(10.0, 400))`, "math.ceil: -Inf doesn't fit in an integer"},
+		{`use math; math.floor(10000000000000000000.5)`, "math.floor: 1e+19 doesn't fit in an integer"},
+	}
+
+	for _, tt := range errors {
+		testError(t, testEval(t, tt.input), tt.expected)
+	}
+
+	result := testEval(t, `use math; math.abs(-9223372036854775807 - 1)`)
+	float, ok := result.(*object.Float)
+	if !ok || float.Value != -float64(math.MinInt64) {
+		t.Errorf("expected math.abs of the smallest integer to be a float, got %s", inspect(result))
+	}
+}
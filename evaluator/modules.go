@@ -0,0 +1,154 @@
+package evaluator
+
+import (
+	"monkey/object"
+	"monkey/parser"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ModuleLoader finds, evaluates and caches the modules imported with `use`.
+// Every module is evaluated at most once per loader, no matter how many files
+// import it. Each Interpreter has its own.
+type ModuleLoader struct {
+	SearchPath []string
+
+	modules map[string]*object.Module
+	loading []string
+}
+
+// nativeModules holds the standard library modules implemented in Go. They
+// are loaded through `use` like any other module, but need no file on disk.
+var nativeModules = map[string]map[string]object.Object{}
+
+func registerNativeModule(name string, members map[string]object.Object) {
+	nativeModules[name] = members
+}
+
+func NewModuleLoader(searchPath []string) *ModuleLoader {
+	return &ModuleLoader{SearchPath: searchPath, modules: make(map[string]*object.Module)}
+}
+
+// DefaultSearchPath is the current directory followed by the directories
+// listed in the MONKEY_PATH environment variable.
+func DefaultSearchPath() []string {
+	searchPath := []string{"."}
+
+	for _, dir := range filepath.SplitList(os.Getenv("MONKEY_PATH")) {
+		if dir != "" {
+			searchPath = append(searchPath, dir)
+		}
+	}
+
+	return searchPath
+}
+
+// SetMainScript tells the loader which script is being run, so that `use` in
+// that script looks next to it first, as it does in modules, and a module
+// importing the script back is reported as a cycle.
+func (ml *ModuleLoader) SetMainScript(path string) {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	ml.loading = []string{path}
+}
+
+// load returns the module called name, such as "utils/strings", evaluating it
+// in the interpreter in if it isn't cached yet. Standard library modules take
+// precedence; other modules are looked up next to the module that is
+// importing them first, and then in every directory of the search path.
+func (ml *ModuleLoader) load(in *Interpreter, name string) object.Object {
+	if members, ok := nativeModules[name]; ok {
+		return ml.loadNative(in, name, members)
+	}
+
+	path, err := ml.resolve(name)
+	if err != nil {
+		return err
+	}
+
+	if module, ok := ml.modules[path]; ok {
+		return module
+	}
+
+	for i, loading := range ml.loading {
+		if loading == path {
+			cycle := append(append([]string{}, ml.loading[i:]...), path)
+			return newError("import cycle: " + strings.Join(cycle, " -> "))
+		}
+	}
+
+	program, parseErr := parser.ParseFile(path)
+	if parseErr != nil {
+		return newError(parseErr.Error())
+	}
+
+	ml.loading = append(ml.loading, path)
+	defer func() {
+		ml.loading = ml.loading[:len(ml.loading)-1]
+	}()
+
+	moduleEnv := object.NewEnvironment()
+	moduleEnv.SetInterpreter(in)
+
+	result := Eval(program, moduleEnv)
+	if result != nil && isError(result) {
+		if err := result.(*object.Error); !err.Exit {
+			return newError("in module " + name + ": " + err.Message)
+		}
+		return result
+	}
+
+	module := &object.Module{Name: filepath.Base(filepath.FromSlash(name)), Path: path, Env: moduleEnv}
+	ml.modules[path] = module
+
+	return module
+}
+
+func (ml *ModuleLoader) loadNative(in *Interpreter, name string, members map[string]object.Object) object.Object {
+	path := "<builtin " + name + ">"
+
+	if module, ok := ml.modules[path]; ok {
+		return module
+	}
+
+	moduleEnv := object.NewEnvironment()
+	for member, value := range members {
+		moduleEnv.SetConstant(member, value)
+	}
+
+	// The members are shared by every interpreter, except for the script
+	// arguments, which each interpreter has its own of.
+	if name == "os" {
+		moduleEnv.SetConstant("args", stringArray(in.Args))
+	}
+
+	module := &object.Module{Name: name, Path: path, Env: moduleEnv}
+	ml.modules[path] = module
+
+	return module
+}
+
+func (ml *ModuleLoader) resolve(name string) (string, *object.Error) {
+	filename := filepath.FromSlash(name) + ".mk"
+
+	dirs := ml.SearchPath
+	if len(ml.loading) > 0 {
+		importer := ml.loading[len(ml.loading)-1]
+		dirs = append([]string{filepath.Dir(importer)}, dirs...)
+	}
+
+	for _, dir := range dirs {
+		path := filepath.Join(dir, filename)
+
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			if abs, err := filepath.Abs(path); err == nil {
+				return abs, nil
+			}
+			return path, nil
+		}
+	}
+
+	return "", newError("module " + name + " not found in " + strings.Join(dirs, ", "))
+}
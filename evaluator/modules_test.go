@@ -0,0 +1,157 @@
+package evaluator
+
+import (
+	"bufio"
+	"io/ioutil"
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/parser"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func evalIn(t *testing.T, input string, in *Interpreter) (*object.Environment, object.Object) {
+	t.Helper()
+
+	l := lexer.New(input, bufio.NewScanner(strings.NewReader("")))
+	p := parser.New(l)
+	program := p.ParseProgram()
+
+	if len(p.Errors()) > 0 {
+		t.Fatalf("parser errors for %q: %v", input, p.Errors())
+	}
+
+	env := object.NewEnvironment()
+	env.SetInterpreter(in)
+
+	return env, Eval(program, env)
+}
+
+func loadModule(t *testing.T, input string, name string, in *Interpreter) *object.Module {
+	t.Helper()
+
+	env, result := evalIn(t, input, in)
+	if result != nil && isError(result) {
+		t.Fatalf("evaluating %q: %s", input, result.Inspect())
+	}
+
+	module, ok := env.Get(name).(*object.Module)
+	if !ok {
+		t.Fatalf("expected %s to be a module, got %s", name, inspect(env.Get(name)))
+	}
+
+	return module
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestModuleCachePerInterpreter(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"util.mk": "let answer = 42\n"})
+
+	first := NewInterpreter()
+	first.Modules = NewModuleLoader([]string{dir})
+	second := NewInterpreter()
+	second.Modules = NewModuleLoader([]string{dir})
+
+	a := loadModule(t, "use util", "util", first)
+	b := loadModule(t, "use util", "util", first)
+	c := loadModule(t, "use util", "util", second)
+
+	if a != b {
+		t.Errorf("expected the same interpreter to return its cached module")
+	}
+
+	if a == c {
+		t.Errorf("expected different interpreters not to share their module cache")
+	}
+
+	testInteger(t, a.Env.Get("answer"), 42)
+}
+
+func TestModuleSearchPath(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+	writeFiles(t, first, map[string]string{"shadowed.mk": "let from = 1\n"})
+	writeFiles(t, second, map[string]string{"shadowed.mk": "let from = 2\n", "only.mk": "let from = 2\n"})
+
+	// -path puts its directories in front of the default search path.
+	interpreter := NewInterpreter()
+	interpreter.Modules = NewModuleLoader([]string{first, second})
+	testInteger(t, loadModule(t, "use shadowed", "shadowed", interpreter).Env.Get("from"), 1)
+	testInteger(t, loadModule(t, "use only", "only", interpreter).Env.Get("from"), 2)
+
+	defer os.Setenv("MONKEY_PATH", os.Getenv("MONKEY_PATH"))
+	os.Setenv("MONKEY_PATH", second+string(os.PathListSeparator)+first)
+
+	interpreter = NewInterpreter()
+	testInteger(t, loadModule(t, "use shadowed", "shadowed", interpreter).Env.Get("from"), 2)
+
+	_, result := evalIn(t, "use missing", interpreter)
+	testError(t, result, "module missing not found in ., "+second+", "+first)
+}
+
+func TestNestedModules(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a/b.mk":  "use c\nlet value = c.value + 1\n",
+		"a/c.mk":  "let value = 1\n",
+		"c.mk":    "let value = 10\n",
+		"main.mk": "use a/b\n",
+	})
+
+	// a/b finds a/c next to itself before c in the search path.
+	interpreter := NewInterpreter()
+	interpreter.Modules = NewModuleLoader([]string{dir})
+	testInteger(t, loadModule(t, "use a/b", "b", interpreter).Env.Get("value"), 2)
+	testInteger(t, loadModule(t, "use a/b as ab", "ab", interpreter).Env.Get("value"), 2)
+
+	// The main script's directory is searched first, wherever the
+	// interpreter runs from.
+	interpreter = NewInterpreter()
+	interpreter.Modules = NewModuleLoader(nil)
+	interpreter.Modules.SetMainScript(filepath.Join(dir, "main.mk"))
+	testInteger(t, loadModule(t, "use c", "c", interpreter).Env.Get("value"), 10)
+}
+
+func TestModuleCycles(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.mk":    "use b\n",
+		"b.mk":    "use a\n",
+		"self.mk": "use self\n",
+		"back.mk": "use main\n",
+		"main.mk": "use back\n",
+	})
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"use a", "in module a: in module b: import cycle: {dir}/a.mk -> {dir}/b.mk -> {dir}/a.mk"},
+		{"use self", "in module self: import cycle: {dir}/self.mk -> {dir}/self.mk"},
+		{"use back", "in module back: import cycle: {dir}/main.mk -> {dir}/back.mk -> {dir}/main.mk"},
+	}
+
+	for _, tt := range tests {
+		interpreter := NewInterpreter()
+		interpreter.Modules = NewModuleLoader([]string{dir})
+		interpreter.Modules.SetMainScript(filepath.Join(dir, "main.mk"))
+
+		_, result := evalIn(t, tt.input, interpreter)
+		testError(t, result, strings.ReplaceAll(tt.expected, "{dir}/", dir+string(filepath.Separator)))
+	}
+}
@@ -0,0 +1,108 @@
+package evaluator
+
+import (
+	"bytes"
+	"monkey/object"
+	"os"
+	"os/exec"
+)
+
+func init() {
+	registerNativeModule("os", map[string]object.Object{
+		"env":  &object.Builtin{Fn: b_os_env},
+		"exit": &object.Builtin{Fn: b_os_exit},
+		"cwd":  &object.Builtin{Fn: b_os_cwd},
+		"run":  &object.Builtin{InterpreterFn: b_os_run},
+	})
+}
+
+// b_os_env returns the value of an environment variable, or the default
+// value (null if not given) when it isn't set.
+func b_os_env(args ...object.Object) object.Object {
+	if err := checkOptionalArgs("os.env", args, 1, object.STRING_OBJ, ""); err != nil {
+		return err
+	}
+
+	value, ok := os.LookupEnv(args[0].(*object.String).Value)
+	if !ok {
+		if len(args) == 2 {
+			return args[1]
+		}
+		return NULL
+	}
+
+	return &object.String{Value: value}
+}
+
+// b_os_exit stops the script. It returns an error flagged as an exit, which
+// unwinds the evaluation like any other error; it's up to the embedder, main
+// in the case of the CLI, to actually end the process.
+func b_os_exit(args ...object.Object) object.Object {
+	if err := checkOptionalArgs("os.exit", args, 0, object.INTEGER_OBJ); err != nil {
+		return err
+	}
+
+	code := 0
+	if len(args) == 1 {
+		code = int(args[0].(*object.Integer).Value)
+	}
+
+	return &object.Error{Message: "exit", Exit: true, ExitCode: code}
+}
+
+func b_os_cwd(args ...object.Object) object.Object {
+	if err := checkArgs("os.cwd", args); err != nil {
+		return err
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return newError("os.cwd: " + err.Error())
+	}
+
+	return &object.String{Value: dir}
+}
+
+// b_os_run runs a program, without a shell, and returns a hash with its exit
+// code and what it wrote to stdout and stderr.
+func b_os_run(interp object.Interpreter, args ...object.Object) object.Object {
+	if err := checkOptionalArgs("os.run", args, 1, object.STRING_OBJ, object.ARRAY_OBJ); err != nil {
+		return err
+	}
+
+	if !interp.(*Interpreter).AllowSubprocesses {
+		return newError("os.run: starting programs is not allowed")
+	}
+
+	if interp.(*Interpreter).Filesystem.IsRestricted() {
+		return newError("os.run: starting programs is not allowed while the filesystem is restricted")
+	}
+
+	cmdArgs := []string{}
+	if len(args) == 2 {
+		for _, arg := range args[1].(*object.Array).Elements {
+			str, ok := arg.(*object.String)
+			if !ok {
+				return newError("os.run: every argument must be a STRING, got " + arg.Type())
+			}
+			cmdArgs = append(cmdArgs, str.Value)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(interp.(*Interpreter).Context, args[0].(*object.String).Value, cmdArgs...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if _, ok := err.(*exec.ExitError); err != nil && !ok {
+		return newError("os.run: " + err.Error())
+	}
+
+	return newStringHash(map[string]object.Object{
+		"code":   &object.Integer{Value: int64(cmd.ProcessState.ExitCode())},
+		"stdout": &object.String{Value: stdout.String()},
+		"stderr": &object.String{Value: stderr.String()},
+	})
+}
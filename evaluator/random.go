@@ -0,0 +1,141 @@
+package evaluator
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"monkey/object"
+)
+
+func init() {
+	registerNativeModule("random", map[string]object.Object{
+		"int":     &object.Builtin{InterpreterFn: b_random_int},
+		"float":   &object.Builtin{InterpreterFn: b_random_float},
+		"choice":  &object.Builtin{InterpreterFn: b_random_choice},
+		"shuffle": &object.Builtin{InterpreterFn: b_random_shuffle},
+		"sample":  &object.Builtin{InterpreterFn: b_random_sample},
+		"uuid":    &object.Builtin{InterpreterFn: b_random_uuid},
+		"seed":    &object.Builtin{InterpreterFn: b_random_seed},
+	})
+}
+
+// b_random_int returns an integer between min and max, both included.
+func b_random_int(interp object.Interpreter, args ...object.Object) object.Object {
+	if err := checkArgs("random.int", args, object.INTEGER_OBJ, object.INTEGER_OBJ); err != nil {
+		return err
+	}
+
+	min, max := args[0].(*object.Integer).Value, args[1].(*object.Integer).Value
+	if min > max {
+		return newError("random.int: min is greater than max")
+	}
+
+	random := interp.(*Interpreter).Random
+
+	// The span is computed on uint64, since max - min overflows an int64 when
+	// the range covers more than half of it.
+	span := uint64(max) - uint64(min)
+	if span < math.MaxInt64 {
+		return &object.Integer{Value: min + random.Int63n(int64(span)+1)}
+	}
+
+	return &object.Integer{Value: int64(uint64(min) + randomUint64n(random, span))}
+}
+
+// randomUint64n returns a number in [0, max], rejecting the values that would
+// make some numbers more likely than others.
+func randomUint64n(random *rand.Rand, max uint64) uint64 {
+	if max == math.MaxUint64 {
+		return random.Uint64()
+	}
+
+	n := max + 1
+	threshold := -n % n
+	for {
+		if value := random.Uint64(); value >= threshold {
+			return value % n
+		}
+	}
+}
+
+// b_random_float returns a float in [0, 1).
+func b_random_float(interp object.Interpreter, args ...object.Object) object.Object {
+	if err := checkArgs("random.float", args); err != nil {
+		return err
+	}
+
+	return &object.Float{Value: interp.(*Interpreter).Random.Float64()}
+}
+
+func b_random_choice(interp object.Interpreter, args ...object.Object) object.Object {
+	if err := checkArgs("random.choice", args, object.ARRAY_OBJ); err != nil {
+		return err
+	}
+
+	elements := args[0].(*object.Array).Elements
+	if len(elements) == 0 {
+		return newError("random.choice: the array is empty")
+	}
+
+	return elements[interp.(*Interpreter).Random.Intn(len(elements))]
+}
+
+// b_random_shuffle returns a shuffled copy of the array.
+func b_random_shuffle(interp object.Interpreter, args ...object.Object) object.Object {
+	if err := checkArgs("random.shuffle", args, object.ARRAY_OBJ); err != nil {
+		return err
+	}
+
+	elements := append([]object.Object{}, args[0].(*object.Array).Elements...)
+	interp.(*Interpreter).Random.Shuffle(len(elements), func(i, j int) {
+		elements[i], elements[j] = elements[j], elements[i]
+	})
+
+	return &object.Array{Elements: elements}
+}
+
+// b_random_sample returns n elements of the array, picked at random without
+// repetition.
+func b_random_sample(interp object.Interpreter, args ...object.Object) object.Object {
+	if err := checkArgs("random.sample", args, object.ARRAY_OBJ, object.INTEGER_OBJ); err != nil {
+		return err
+	}
+
+	elements := args[0].(*object.Array).Elements
+	n := args[1].(*object.Integer).Value
+
+	if n < 0 || n > int64(len(elements)) {
+		return newError(fmt.Sprintf("random.sample: can't pick %d elements from an array of %d", n, len(elements)))
+	}
+
+	sample := make([]object.Object, n)
+	for i, index := range interp.(*Interpreter).Random.Perm(len(elements))[:n] {
+		sample[i] = elements[index]
+	}
+
+	return &object.Array{Elements: sample}
+}
+
+// b_random_uuid returns a version 4 UUID.
+func b_random_uuid(interp object.Interpreter, args ...object.Object) object.Object {
+	if err := checkArgs("random.uuid", args); err != nil {
+		return err
+	}
+
+	var uuid [16]byte
+	interp.(*Interpreter).Random.Read(uuid[:])
+	uuid[6] = (uuid[6] & 0x0f) | 0x40
+	uuid[8] = (uuid[8] & 0x3f) | 0x80
+
+	return &object.String{Value: fmt.Sprintf("%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:])}
+}
+
+func b_random_seed(interp object.Interpreter, args ...object.Object) object.Object {
+	if err := checkArgs("random.seed", args, object.INTEGER_OBJ); err != nil {
+		return err
+	}
+
+	interp.(*Interpreter).Random.Seed(args[0].(*object.Integer).Value)
+
+	return NULL
+}
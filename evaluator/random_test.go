@@ -0,0 +1,64 @@
+package evaluator
+
+import (
+	"math"
+	"math/rand"
+	"monkey/object"
+	"testing"
+)
+
+func TestRandomIntRange(t *testing.T) {
+	in := NewInterpreter()
+	in.Random = rand.New(rand.NewSource(1))
+
+	tests := []struct {
+		min, max int64
+	}{
+		{0, 0},
+		{-3, 3},
+		{0, math.MaxInt64},
+		{-1, math.MaxInt64},
+		{math.MinInt64, 0},
+		{math.MinInt64, math.MaxInt64},
+		{math.MaxInt64, math.MaxInt64},
+	}
+
+	for _, tt := range tests {
+		for i := 0; i < 100; i++ {
+			result := b_random_int(in, &object.Integer{Value: tt.min}, &object.Integer{Value: tt.max})
+
+			integer, ok := result.(*object.Integer)
+			if !ok {
+				t.Fatalf("random.int(%d, %d) returned %s", tt.min, tt.max, result.Inspect())
+			}
+
+			if integer.Value < tt.min || integer.Value > tt.max {
+				t.Fatalf("random.int(%d, %d) returned %d", tt.min, tt.max, integer.Value)
+			}
+		}
+	}
+}
+
+func TestRandomPerInterpreter(t *testing.T) {
+	input := `use random; [random.int(0, 1000000), random.uuid(), random.shuffle([1, 2, 3, 4, 5])]`
+
+	first := NewInterpreter()
+	first.Random = rand.New(rand.NewSource(42))
+	second := NewInterpreter()
+	second.Random = rand.New(rand.NewSource(42))
+
+	_, a := evalIn(t, input, first)
+	_, b := evalIn(t, input, second)
+	if a.Inspect() != b.Inspect() {
+		t.Errorf("expected interpreters with the same seed to agree, got %s and %s", a.Inspect(), b.Inspect())
+	}
+
+	// Seeding one interpreter doesn't change the numbers of another one.
+	evalIn(t, `use random; random.seed(7)`, first)
+
+	_, a = evalIn(t, input, first)
+	_, b = evalIn(t, input, second)
+	if a.Inspect() == b.Inspect() {
+		t.Errorf("expected random.seed to only affect its own interpreter")
+	}
+}
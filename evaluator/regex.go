@@ -0,0 +1,198 @@
+package evaluator
+
+import (
+	"fmt"
+	"monkey/object"
+	"regexp"
+)
+
+// Every function of the regex module takes either a compiled regex or a
+// pattern string, which is compiled on each call.
+func init() {
+	registerNativeModule("regex", map[string]object.Object{
+		"compile":  &object.Builtin{Fn: b_regex_compile},
+		"match":    &object.Builtin{Fn: b_regex_match},
+		"find":     &object.Builtin{Fn: b_regex_find},
+		"find_all": &object.Builtin{Fn: b_regex_find_all},
+		"captures": &object.Builtin{Fn: b_regex_captures},
+		"replace":  &object.Builtin{InterpreterFn: b_regex_replace},
+		"split":    &object.Builtin{Fn: b_regex_split},
+	})
+}
+
+func toRegex(name string, obj object.Object) (*regexp.Regexp, *object.Error) {
+	switch obj := obj.(type) {
+	case *object.Regex:
+		return obj.Value, nil
+	case *object.String:
+		re, err := regexp.Compile(obj.Value)
+		if err != nil {
+			return nil, newError(name + ": " + err.Error())
+		}
+		return re, nil
+	default:
+		return nil, newError(name + ": the pattern must be a REGEX or a STRING, got " + obj.Type())
+	}
+}
+
+// regexArgs checks the arguments shared by most of the module: a pattern, a
+// string and the given optional types.
+func regexArgs(name string, args []object.Object, optional ...string) (*regexp.Regexp, string, *object.Error) {
+	types := append([]string{"", object.STRING_OBJ}, optional...)
+	if err := checkOptionalArgs(name, args, 2, types...); err != nil {
+		return nil, "", err
+	}
+
+	re, err := toRegex(name, args[0])
+	if err != nil {
+		return nil, "", err
+	}
+
+	return re, args[1].(*object.String).Value, nil
+}
+
+func b_regex_compile(args ...object.Object) object.Object {
+	if err := checkArgs("regex.compile", args, object.STRING_OBJ); err != nil {
+		return err
+	}
+
+	re, err := toRegex("regex.compile", args[0])
+	if err != nil {
+		return err
+	}
+
+	return &object.Regex{Value: re}
+}
+
+func b_regex_match(args ...object.Object) object.Object {
+	re, s, err := regexArgs("regex.match", args)
+	if err != nil {
+		return err
+	}
+
+	return nativeBoolToBooleanObject(re.MatchString(s))
+}
+
+// b_regex_find returns the first match, or null if there is none.
+func b_regex_find(args ...object.Object) object.Object {
+	re, s, err := regexArgs("regex.find", args)
+	if err != nil {
+		return err
+	}
+
+	match := re.FindStringIndex(s)
+	if match == nil {
+		return NULL
+	}
+
+	return &object.String{Value: s[match[0]:match[1]]}
+}
+
+// b_regex_find_all returns every match, or only the first n ones.
+func b_regex_find_all(args ...object.Object) object.Object {
+	re, s, err := regexArgs("regex.find_all", args, object.INTEGER_OBJ)
+	if err != nil {
+		return err
+	}
+
+	n := -1
+	if len(args) == 3 {
+		n = int(args[2].(*object.Integer).Value)
+	}
+
+	return stringArray(re.FindAllString(s, n))
+}
+
+// b_regex_captures returns a hash with the text of every group of the first
+// match, keyed by the group's name when it has one and by its number
+// otherwise, 0 being the whole match. It returns null if nothing matches.
+func b_regex_captures(args ...object.Object) object.Object {
+	re, s, err := regexArgs("regex.captures", args)
+	if err != nil {
+		return err
+	}
+
+	match := re.FindStringSubmatchIndex(s)
+	if match == nil {
+		return NULL
+	}
+
+	hash := object.NewHash()
+
+	for i, name := range re.SubexpNames() {
+		var value object.Object = NULL
+		if match[2*i] >= 0 {
+			value = &object.String{Value: s[match[2*i]:match[2*i+1]]}
+		}
+
+		var key object.Object = &object.Integer{Value: int64(i)}
+		if name != "" {
+			key = &object.String{Value: name}
+		}
+
+		hash.Pairs[key.(object.Hashable).HashKey()] = object.HashPair{Key: key, Value: value}
+	}
+
+	return hash
+}
+
+// b_regex_replace replaces every match with either a string, where $1 or
+// ${name} expand to the matching group, or with the result of calling a
+// function with the matched text.
+func b_regex_replace(interp object.Interpreter, args ...object.Object) object.Object {
+	if len(args) != 3 {
+		return newError(fmt.Sprintf("regex.replace: invalid number of arguments, want 3, got %d", len(args)))
+	}
+
+	re, s, err := regexArgs("regex.replace", args[:2])
+	if err != nil {
+		return err
+	}
+
+	switch replacement := args[2].(type) {
+	case *object.String:
+		return &object.String{Value: re.ReplaceAllString(s, replacement.Value)}
+	case *object.Function, *object.Builtin:
+		var callErr object.Object
+
+		result := re.ReplaceAllStringFunc(s, func(match string) string {
+			if callErr != nil {
+				return match
+			}
+
+			value := interp.Call(replacement, &object.String{Value: match})
+			if str, ok := value.(*object.String); ok {
+				return str.Value
+			}
+
+			if isError(value) {
+				callErr = value
+			} else {
+				callErr = newError("regex.replace: the replacement function must return a STRING, got " + value.Type())
+			}
+			return match
+		})
+
+		if callErr != nil {
+			return callErr
+		}
+
+		return &object.String{Value: result}
+	default:
+		return newError("regex.replace: the replacement must be a STRING or a function, got " + replacement.Type())
+	}
+}
+
+func b_regex_split(args ...object.Object) object.Object {
+	re, s, err := regexArgs("regex.split", args, object.INTEGER_OBJ)
+	if err != nil {
+		return err
+	}
+
+	n := -1
+	if len(args) == 3 {
+		n = int(args[2].(*object.Integer).Value)
+	}
+
+	return stringArray(re.Split(s, n))
+}
@@ -0,0 +1,245 @@
+package evaluator
+
+import (
+	"fmt"
+	"monkey/object"
+	"strings"
+	"unicode/utf8"
+)
+
+func init() {
+	registerNativeModule("strings", map[string]object.Object{
+		"split":       &object.Builtin{Fn: b_strings_split},
+		"join":        &object.Builtin{Fn: b_strings_join},
+		"trim":        &object.Builtin{Fn: b_strings_trim},
+		"upper":       &object.Builtin{Fn: b_strings_upper},
+		"lower":       &object.Builtin{Fn: b_strings_lower},
+		"contains":    &object.Builtin{Fn: b_strings_contains},
+		"starts_with": &object.Builtin{Fn: b_strings_starts_with},
+		"ends_with":   &object.Builtin{Fn: b_strings_ends_with},
+		"replace":     &object.Builtin{Fn: b_strings_replace},
+		"index_of":    &object.Builtin{Fn: b_strings_index_of},
+		"repeat":      &object.Builtin{Fn: b_strings_repeat},
+		"pad_left":    &object.Builtin{Fn: b_strings_pad_left},
+		"pad_right":   &object.Builtin{Fn: b_strings_pad_right},
+		"chars":       &object.Builtin{Fn: b_strings_chars},
+	})
+}
+
+func stringArray(values []string) *object.Array {
+	elements := make([]object.Object, len(values))
+	for i, v := range values {
+		elements[i] = &object.String{Value: v}
+	}
+	return &object.Array{Elements: elements}
+}
+
+func b_strings_split(args ...object.Object) object.Object {
+	if err := checkArgs("strings.split", args, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+		return err
+	}
+
+	s, sep := args[0].(*object.String).Value, args[1].(*object.String).Value
+
+	return stringArray(strings.Split(s, sep))
+}
+
+func b_strings_join(args ...object.Object) object.Object {
+	if err := checkOptionalArgs("strings.join", args, 1, object.ARRAY_OBJ, object.STRING_OBJ); err != nil {
+		return err
+	}
+
+	sep := ""
+	if len(args) == 2 {
+		sep = args[1].(*object.String).Value
+	}
+
+	parts := []string{}
+	for _, element := range args[0].(*object.Array).Elements {
+		str, ok := element.(*object.String)
+		if !ok {
+			return newError("strings.join: every element must be a STRING, got " + element.Type())
+		}
+		parts = append(parts, str.Value)
+	}
+
+	return &object.String{Value: strings.Join(parts, sep)}
+}
+
+// b_strings_trim removes leading and trailing whitespace, or the characters
+// in the optional cutset.
+func b_strings_trim(args ...object.Object) object.Object {
+	if err := checkOptionalArgs("strings.trim", args, 1, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+		return err
+	}
+
+	s := args[0].(*object.String).Value
+
+	if len(args) == 2 {
+		return &object.String{Value: strings.Trim(s, args[1].(*object.String).Value)}
+	}
+
+	return &object.String{Value: strings.TrimSpace(s)}
+}
+
+func b_strings_upper(args ...object.Object) object.Object {
+	if err := checkArgs("strings.upper", args, object.STRING_OBJ); err != nil {
+		return err
+	}
+
+	return &object.String{Value: strings.ToUpper(args[0].(*object.String).Value)}
+}
+
+func b_strings_lower(args ...object.Object) object.Object {
+	if err := checkArgs("strings.lower", args, object.STRING_OBJ); err != nil {
+		return err
+	}
+
+	return &object.String{Value: strings.ToLower(args[0].(*object.String).Value)}
+}
+
+func b_strings_contains(args ...object.Object) object.Object {
+	if err := checkArgs("strings.contains", args, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+		return err
+	}
+
+	return nativeBoolToBooleanObject(strings.Contains(args[0].(*object.String).Value, args[1].(*object.String).Value))
+}
+
+func b_strings_starts_with(args ...object.Object) object.Object {
+	if err := checkArgs("strings.starts_with", args, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+		return err
+	}
+
+	return nativeBoolToBooleanObject(strings.HasPrefix(args[0].(*object.String).Value, args[1].(*object.String).Value))
+}
+
+func b_strings_ends_with(args ...object.Object) object.Object {
+	if err := checkArgs("strings.ends_with", args, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+		return err
+	}
+
+	return nativeBoolToBooleanObject(strings.HasSuffix(args[0].(*object.String).Value, args[1].(*object.String).Value))
+}
+
+// b_strings_replace replaces every occurrence of old with new, or only the
+// first n ones when n is given.
+func b_strings_replace(args ...object.Object) object.Object {
+	if err := checkOptionalArgs("strings.replace", args, 3, object.STRING_OBJ, object.STRING_OBJ, object.STRING_OBJ, object.INTEGER_OBJ); err != nil {
+		return err
+	}
+
+	n := -1
+	if len(args) == 4 {
+		n = int(args[3].(*object.Integer).Value)
+	}
+
+	s, old, new := args[0].(*object.String).Value, args[1].(*object.String).Value, args[2].(*object.String).Value
+
+	return &object.String{Value: strings.Replace(s, old, new, n)}
+}
+
+// b_strings_index_of returns the position, in characters, of the first
+// occurrence of sub, or -1 if it isn't there.
+func b_strings_index_of(args ...object.Object) object.Object {
+	if err := checkArgs("strings.index_of", args, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+		return err
+	}
+
+	s, sub := args[0].(*object.String).Value, args[1].(*object.String).Value
+
+	index := strings.Index(s, sub)
+	if index < 0 {
+		return &object.Integer{Value: -1}
+	}
+
+	return &object.Integer{Value: int64(utf8.RuneCountInString(s[:index]))}
+}
+
+// maxStringLength bounds the strings built by repeat and the pad functions, so
+// a huge count is reported as an error instead of exhausting memory.
+const maxStringLength = 1 << 24
+
+func b_strings_repeat(args ...object.Object) object.Object {
+	if err := checkArgs("strings.repeat", args, object.STRING_OBJ, object.INTEGER_OBJ); err != nil {
+		return err
+	}
+
+	s := args[0].(*object.String).Value
+	count := args[1].(*object.Integer).Value
+	if count < 0 {
+		return newError("strings.repeat: count must not be negative")
+	}
+
+	if len(s) > 0 && count > int64(maxStringLength/len(s)) {
+		return newError(fmt.Sprintf("strings.repeat: the result would be longer than %d bytes", maxStringLength))
+	}
+
+	return &object.String{Value: strings.Repeat(s, int(count))}
+}
+
+func b_strings_pad_left(args ...object.Object) object.Object {
+	return pad("strings.pad_left", args, true)
+}
+
+func b_strings_pad_right(args ...object.Object) object.Object {
+	return pad("strings.pad_right", args, false)
+}
+
+// pad fills s with the pad string (a space by default) until it is width
+// characters long.
+func pad(name string, args []object.Object, left bool) object.Object {
+	if err := checkOptionalArgs(name, args, 2, object.STRING_OBJ, object.INTEGER_OBJ, object.STRING_OBJ); err != nil {
+		return err
+	}
+
+	s := args[0].(*object.String).Value
+	width := args[1].(*object.Integer).Value
+
+	if width > maxStringLength {
+		return newError(fmt.Sprintf("%s: the width must not be greater than %d", name, maxStringLength))
+	}
+
+	padding := " "
+	if len(args) == 3 {
+		padding = args[2].(*object.String).Value
+	}
+
+	if padding == "" {
+		return newError(name + ": the padding string must not be empty")
+	}
+
+	missing := int(width) - utf8.RuneCountInString(s)
+	if missing <= 0 {
+		return &object.String{Value: s}
+	}
+
+	paddingRunes := []rune(padding)
+
+	var fill strings.Builder
+	for i := 0; i < missing; i++ {
+		fill.WriteRune(paddingRunes[i%len(paddingRunes)])
+
+		if fill.Len()+len(s) > maxStringLength {
+			return newError(fmt.Sprintf("%s: the result would be longer than %d bytes", name, maxStringLength))
+		}
+	}
+
+	if left {
+		return &object.String{Value: fill.String() + s}
+	}
+	return &object.String{Value: s + fill.String()}
+}
+
+func b_strings_chars(args ...object.Object) object.Object {
+	if err := checkArgs("strings.chars", args, object.STRING_OBJ); err != nil {
+		return err
+	}
+
+	chars := []string{}
+	for _, r := range args[0].(*object.String).Value {
+		chars = append(chars, string(r))
+	}
+
+	return stringArray(chars)
+}
@@ -0,0 +1,32 @@
+package evaluator
+
+import "testing"
+
+func TestPad(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`use strings; strings.pad_left("ab", 5)`, "   ab"},
+		{`use strings; strings.pad_right("ab", 5, "xyz")`, "abxyz"},
+		{`use strings; strings.pad_left("ab", 6, "é-")`, "é-é-ab"},
+		{`use strings; strings.pad_left("abc", 2)`, "abc"},
+	}
+
+	for _, tt := range tests {
+		testString(t, testEval(t, tt.input), tt.expected)
+	}
+
+	errors := []struct {
+		input    string
+		expected string
+	}{
+		{`use strings; strings.pad_left("a", 1073741824)`, "strings.pad_left: the width must not be greater than 16777216"},
+		{`use strings; strings.pad_right("a", 16777216, "é")`, "strings.pad_right: the result would be longer than 16777216 bytes"},
+		{`use strings; strings.pad_left("a", 3, "")`, "strings.pad_left: the padding string must not be empty"},
+	}
+
+	for _, tt := range errors {
+		testError(t, testEval(t, tt.input), tt.expected)
+	}
+}
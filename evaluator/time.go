@@ -0,0 +1,162 @@
+package evaluator
+
+import (
+	"monkey/object"
+	"time"
+)
+
+// Durations are integers counting milliseconds, the unit taken by sleep. A
+// time plus or minus a duration is a time, and the difference between two
+// times is a duration.
+func init() {
+	registerNativeModule("time", map[string]object.Object{
+		"now":     &object.Builtin{Fn: b_time_now},
+		"unix":    &object.Builtin{Fn: b_time_unix},
+		"sleep":   &object.Builtin{InterpreterFn: b_time_sleep},
+		"format":  &object.Builtin{Fn: b_time_format},
+		"parse":   &object.Builtin{Fn: b_time_parse},
+		"add":     &object.Builtin{Fn: b_time_add},
+		"diff":    &object.Builtin{Fn: b_time_diff},
+		"seconds": &object.Builtin{Fn: durationBuiltin("time.seconds", time.Second)},
+		"minutes": &object.Builtin{Fn: durationBuiltin("time.minutes", time.Minute)},
+		"hours":   &object.Builtin{Fn: durationBuiltin("time.hours", time.Hour)},
+		"rfc3339": &object.String{Value: time.RFC3339},
+		"date":    &object.String{Value: "2006-01-02"},
+	})
+}
+
+func toMillis(d time.Duration) int64 {
+	return int64(d / time.Millisecond)
+}
+
+func fromMillis(ms int64) time.Duration {
+	return time.Duration(ms) * time.Millisecond
+}
+
+func b_time_now(args ...object.Object) object.Object {
+	if err := checkArgs("time.now", args); err != nil {
+		return err
+	}
+
+	return &object.Time{Value: time.Now()}
+}
+
+// b_time_unix returns the number of seconds since the Unix epoch, for the
+// given time or for now.
+func b_time_unix(args ...object.Object) object.Object {
+	if err := checkOptionalArgs("time.unix", args, 0, object.TIME_OBJ); err != nil {
+		return err
+	}
+
+	t := time.Now()
+	if len(args) == 1 {
+		t = args[0].(*object.Time).Value
+	}
+
+	return &object.Integer{Value: t.Unix()}
+}
+
+func b_time_sleep(interp object.Interpreter, args ...object.Object) object.Object {
+	if err := checkArgs("time.sleep", args, object.INTEGER_OBJ); err != nil {
+		return err
+	}
+
+	ctx := interp.(*Interpreter).Context
+
+	timer := time.NewTimer(fromMillis(args[0].(*object.Integer).Value))
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return NULL
+	case <-ctx.Done():
+		return newError("time.sleep: interrupted: " + ctx.Err().Error())
+	}
+}
+
+// b_time_format uses Go layouts, such as time.date or "15:04:05", and
+// defaults to RFC 3339.
+func b_time_format(args ...object.Object) object.Object {
+	if err := checkOptionalArgs("time.format", args, 1, object.TIME_OBJ, object.STRING_OBJ); err != nil {
+		return err
+	}
+
+	layout := time.RFC3339
+	if len(args) == 2 {
+		layout = args[1].(*object.String).Value
+	}
+
+	return &object.String{Value: args[0].(*object.Time).Value.Format(layout)}
+}
+
+func b_time_parse(args ...object.Object) object.Object {
+	if err := checkOptionalArgs("time.parse", args, 1, object.STRING_OBJ, object.STRING_OBJ); err != nil {
+		return err
+	}
+
+	layout := time.RFC3339
+	if len(args) == 2 {
+		layout = args[1].(*object.String).Value
+	}
+
+	t, err := time.Parse(layout, args[0].(*object.String).Value)
+	if err != nil {
+		return newError("time.parse: " + err.Error())
+	}
+
+	return &object.Time{Value: t}
+}
+
+func b_time_add(args ...object.Object) object.Object {
+	if err := checkArgs("time.add", args, object.TIME_OBJ, object.INTEGER_OBJ); err != nil {
+		return err
+	}
+
+	return &object.Time{Value: args[0].(*object.Time).Value.Add(fromMillis(args[1].(*object.Integer).Value))}
+}
+
+// b_time_diff returns a - b in milliseconds.
+func b_time_diff(args ...object.Object) object.Object {
+	if err := checkArgs("time.diff", args, object.TIME_OBJ, object.TIME_OBJ); err != nil {
+		return err
+	}
+
+	return &object.Integer{Value: toMillis(args[0].(*object.Time).Value.Sub(args[1].(*object.Time).Value))}
+}
+
+func durationBuiltin(name string, unit time.Duration) object.BuiltinFunction {
+	return func(args ...object.Object) object.Object {
+		if err := checkArgs(name, args, object.INTEGER_OBJ); err != nil {
+			return err
+		}
+
+		return &object.Integer{Value: toMillis(time.Duration(args[0].(*object.Integer).Value) * unit)}
+	}
+}
+
+func evalTimeInfixExpression(operator string, left *object.Time, right object.Object) object.Object {
+	switch right := right.(type) {
+	case *object.Integer:
+		switch operator {
+		case "+":
+			return &object.Time{Value: left.Value.Add(fromMillis(right.Value))}
+		case "-":
+			return &object.Time{Value: left.Value.Add(-fromMillis(right.Value))}
+		}
+	case *object.Time:
+		switch operator {
+		case "-":
+			return &object.Integer{Value: toMillis(left.Value.Sub(right.Value))}
+		case "==":
+			return nativeBoolToBooleanObject(left.Value.Equal(right.Value))
+		case "!=":
+			return nativeBoolToBooleanObject(!left.Value.Equal(right.Value))
+		case "<":
+			return nativeBoolToBooleanObject(left.Value.Before(right.Value))
+		case ">":
+			return nativeBoolToBooleanObject(left.Value.After(right.Value))
+		}
+	}
+
+	return newError("unknown operator: TIME " + operator + " " + right.Type())
+}
@@ -4,14 +4,16 @@ import (
 	"bufio"
 	"fmt"
 	"monkey/token"
+	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
 type Lexer struct {
 	Input        string
 	position     int
 	nextPosition int
-	char         byte
+	char         rune
 	scanner      *bufio.Scanner
 }
 
@@ -21,33 +23,47 @@ func New(Input string, scanner *bufio.Scanner) *Lexer {
 	return lexer
 }
 
+// ReadChar decodes the next UTF-8 character of the input. position and
+// nextPosition are byte offsets, so they can be used to slice Input.
 func (l *Lexer) ReadChar() {
 
-	if l.nextPosition >= len(l.Input) {
-		l.char = 0
-
-		if l.scanner.Scan() {
-			if len(l.scanner.Text()) == 0 {
-				l.ReadChar()
-				return
-			}
-			l.Input = l.Input + " " + l.scanner.Text()
-			l.char = l.Input[l.nextPosition]
+	if l.nextPosition >= len(l.Input) && l.scanner.Scan() {
+		if len(l.scanner.Text()) == 0 {
+			l.ReadChar()
+			return
 		}
-
-	} else {
-		l.char = l.Input[l.nextPosition]
+		l.Input = l.Input + " " + l.scanner.Text()
 	}
 
 	l.position = l.nextPosition
-	l.nextPosition += 1
+
+	if l.nextPosition >= len(l.Input) {
+		l.char = 0
+		return
+	}
+
+	char, size := utf8.DecodeRuneInString(l.Input[l.nextPosition:])
+	l.char = char
+	l.nextPosition += size
+}
+
+func (l *Lexer) lookAhead() rune {
+	return l.lookAheadAt(0)
 }
 
-func (l *Lexer) lookAhead() byte {
-	if l.nextPosition > len(l.Input) {
+func (l *Lexer) lookAheadAt(offset int) rune {
+	position := l.nextPosition
+	for ; offset > 0 && position < len(l.Input); offset-- {
+		_, size := utf8.DecodeRuneInString(l.Input[position:])
+		position += size
+	}
+
+	if position >= len(l.Input) {
 		return 0
 	}
-	return l.Input[l.nextPosition]
+
+	char, _ := utf8.DecodeRuneInString(l.Input[position:])
+	return char
 }
 
 func (l *Lexer) NextToken() token.Token {
@@ -60,6 +76,9 @@ func (l *Lexer) NextToken() token.Token {
 		if l.lookAhead() == '=' {
 			tok = token.Token{Type: token.EQUAL, Literal: string(l.char) + string(l.Input[l.nextPosition])}
 			l.ReadChar()
+		} else if l.lookAhead() == '>' {
+			tok = token.Token{Type: token.ARROW, Literal: string(l.char) + string(l.Input[l.nextPosition])}
+			l.ReadChar()
 		} else {
 			tok = newToken(token.ASSIGN, l.char)
 		}
@@ -70,6 +89,27 @@ func (l *Lexer) NextToken() token.Token {
 		} else {
 			tok = newToken(token.NOT, l.char)
 		}
+	case '|':
+		if l.lookAhead() == '>' {
+			tok = token.Token{Type: token.PIPE, Literal: string(l.char) + string(l.Input[l.nextPosition])}
+			l.ReadChar()
+		} else {
+			tok = newToken(token.ILLEGAL, l.char)
+		}
+	case '?':
+		switch l.lookAhead() {
+		case '?':
+			tok = token.Token{Type: token.COALESCE, Literal: "??"}
+			l.ReadChar()
+		case '.':
+			tok = token.Token{Type: token.OPT_DOT, Literal: "?."}
+			l.ReadChar()
+		case '[':
+			tok = token.Token{Type: token.OPT_LSQBRACKET, Literal: "?["}
+			l.ReadChar()
+		default:
+			tok = newToken(token.ILLEGAL, l.char)
+		}
 	case '"':
 		tok.Type = token.STRING
 		tok.Literal = l.readString()
@@ -79,6 +119,8 @@ func (l *Lexer) NextToken() token.Token {
 		tok = newToken(token.GREATER_THAN, l.char)
 	case ';':
 		tok = newToken(token.SEMICOLON, l.char)
+	case ':':
+		tok = newToken(token.COLON, l.char)
 	case '(':
 		tok = newToken(token.LPAREN, l.char)
 	case ')':
@@ -88,7 +130,13 @@ func (l *Lexer) NextToken() token.Token {
 	case ']':
 		tok = newToken(token.RSQBRACKET, l.char)
 	case '.':
-		tok = newToken(token.DOT, l.char)
+		if l.lookAhead() == '.' && l.lookAheadAt(1) == '.' {
+			tok = token.Token{Type: token.ELLIPSIS, Literal: "..."}
+			l.ReadChar()
+			l.ReadChar()
+		} else {
+			tok = newToken(token.DOT, l.char)
+		}
 	case ',':
 		tok = newToken(token.COMMA, l.char)
 	case '+':
@@ -107,13 +155,27 @@ func (l *Lexer) NextToken() token.Token {
 		tok.Literal = ""
 		tok.Type = token.EOF
 	default:
-		if unicode.IsLetter(rune(l.char)) {
+		if isIdentifierStart(l.char) {
 			tok.Literal = l.readIdentifier()
 			tok.Type = token.GetIdentType(tok.Literal)
 			return tok
-		} else if unicode.IsNumber(rune(l.char)) {
-			tok.Type = token.INT
+		} else if isDigit(l.char) {
 			tok.Literal = l.readNumber()
+			tok.Type = token.INT
+			if strings.Contains(tok.Literal, ".") {
+				tok.Type = token.FLOAT
+			}
+
+			// 2x is neither a number nor an identifier, keep it whole so the
+			// error points at it instead of at whatever follows it.
+			if isIdentifierChar(l.char) {
+				position := l.position
+				for isIdentifierChar(l.char) {
+					l.ReadChar()
+				}
+				tok.Literal += l.Input[position:l.position]
+				tok.Type = token.ILLEGAL
+			}
 			return tok
 		} else {
 			tok = newToken(token.ILLEGAL, l.char)
@@ -125,7 +187,7 @@ func (l *Lexer) NextToken() token.Token {
 
 func (l *Lexer) readIdentifier() string {
 	position := l.position
-	for unicode.IsLetter(rune(l.char)) {
+	for isIdentifierChar(l.char) {
 		l.ReadChar()
 	}
 	return l.Input[position:l.position]
@@ -147,13 +209,36 @@ func (l *Lexer) readString() string {
 
 func (l *Lexer) readNumber() string {
 	position := l.position
-	for unicode.IsNumber(rune(l.char)) {
+	for isDigit(l.char) {
 		l.ReadChar()
 	}
+
+	if l.char == '.' && isDigit(l.lookAhead()) {
+		l.ReadChar()
+		for isDigit(l.char) {
+			l.ReadChar()
+		}
+	}
+
 	return l.Input[position:l.position]
 }
 
-func newToken(tokenType string, tokenValue byte) token.Token {
+// Identifiers start with a letter or an underscore, followed by any number
+// of letters, digits and underscores.
+func isIdentifierStart(char rune) bool {
+	return unicode.IsLetter(char) || char == '_'
+}
+
+func isIdentifierChar(char rune) bool {
+	return isIdentifierStart(char) || unicode.IsDigit(char)
+}
+
+// Number literals only use ASCII digits, which is what strconv accepts.
+func isDigit(char rune) bool {
+	return '0' <= char && char <= '9'
+}
+
+func newToken(tokenType string, tokenValue rune) token.Token {
 	return token.Token{Type: tokenType, Literal: string(tokenValue)}
 }
 
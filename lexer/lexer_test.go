@@ -0,0 +1,74 @@
+package lexer
+
+import (
+	"bufio"
+	"monkey/token"
+	"strings"
+	"testing"
+)
+
+func TestIdentifiers(t *testing.T) {
+	input := `let2 let user_id _tmp x2 _ __init__ größe 名前 x٣ 2x 1_000 1.5x 42 3.14 iffy if fn function returned return null nullable`
+
+	tests := []token.Token{
+		{Type: token.IDENTIFIER, Literal: "let2"},
+		{Type: token.LET, Literal: "let"},
+		{Type: token.IDENTIFIER, Literal: "user_id"},
+		{Type: token.IDENTIFIER, Literal: "_tmp"},
+		{Type: token.IDENTIFIER, Literal: "x2"},
+		{Type: token.IDENTIFIER, Literal: "_"},
+		{Type: token.IDENTIFIER, Literal: "__init__"},
+		{Type: token.IDENTIFIER, Literal: "größe"},
+		{Type: token.IDENTIFIER, Literal: "名前"},
+		{Type: token.IDENTIFIER, Literal: "x٣"},
+		{Type: token.ILLEGAL, Literal: "2x"},
+		{Type: token.ILLEGAL, Literal: "1_000"},
+		{Type: token.ILLEGAL, Literal: "1.5x"},
+		{Type: token.INT, Literal: "42"},
+		{Type: token.FLOAT, Literal: "3.14"},
+		{Type: token.IDENTIFIER, Literal: "iffy"},
+		{Type: token.IF, Literal: "if"},
+		{Type: token.FUNCTION, Literal: "fn"},
+		{Type: token.IDENTIFIER, Literal: "function"},
+		{Type: token.IDENTIFIER, Literal: "returned"},
+		{Type: token.RETURN, Literal: "return"},
+		{Type: token.NULL, Literal: "null"},
+		{Type: token.IDENTIFIER, Literal: "nullable"},
+		{Type: token.EOF, Literal: ""},
+	}
+
+	l := New(input, bufio.NewScanner(strings.NewReader("")))
+
+	for i, expected := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != expected.Type || tok.Literal != expected.Literal {
+			t.Fatalf("token %d: expected %s %q, got %s %q", i, expected.Type, expected.Literal, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestIdentifiersInExpressions(t *testing.T) {
+	input := `let x2=user_id+_tmp;`
+
+	tests := []token.Token{
+		{Type: token.LET, Literal: "let"},
+		{Type: token.IDENTIFIER, Literal: "x2"},
+		{Type: token.ASSIGN, Literal: "="},
+		{Type: token.IDENTIFIER, Literal: "user_id"},
+		{Type: token.PLUS, Literal: "+"},
+		{Type: token.IDENTIFIER, Literal: "_tmp"},
+		{Type: token.SEMICOLON, Literal: ";"},
+		{Type: token.EOF, Literal: ""},
+	}
+
+	l := New(input, bufio.NewScanner(strings.NewReader("")))
+
+	for i, expected := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != expected.Type || tok.Literal != expected.Literal {
+			t.Fatalf("token %d: expected %s %q, got %s %q", i, expected.Type, expected.Literal, tok.Type, tok.Literal)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"monkey/evaluator"
 	"monkey/lexer"
@@ -9,41 +10,76 @@ import (
 	"monkey/parser"
 	"monkey/repl"
 	"os"
+	"path/filepath"
 )
 
 func main() {
+	searchPath := flag.String("path", "", "extra directories to look for modules in, separated by "+string(os.PathListSeparator))
+	fsRoots := flag.String("fs-root", "", "directories the fs module is limited to, separated by "+string(os.PathListSeparator))
+	fsReadOnly := flag.Bool("fs-readonly", false, "forbid the fs module from modifying the filesystem")
+	noRun := flag.Bool("no-run", false, "forbid os.run from starting programs, which is implied by -fs-root and -fs-readonly")
+	flag.Parse()
 
-	if len(os.Args) == 1 {
+	interpreter := evaluator.NewInterpreter()
+
+	if *searchPath != "" {
+		interpreter.Modules.SearchPath = append(filepath.SplitList(*searchPath), interpreter.Modules.SearchPath...)
+	}
+
+	interpreter.Filesystem = evaluator.FSPermissions{
+		AllowedRoots: filepath.SplitList(*fsRoots),
+		ReadOnly:     *fsReadOnly,
+	}
+	interpreter.AllowSubprocesses = !*noRun
+
+	if flag.NArg() == 0 {
 		fmt.Println("Starting...")
-		repl.Start(os.Stdin, os.Stdout)
+		repl.Start(os.Stdin, os.Stdout, interpreter)
 	} else {
 
-		file, err := os.Open(fmt.Sprintf("./%s", os.Args[1]))
-		if err != nil {
-			fmt.Print("Error: ", err.Error())
-			os.Exit(1)
-		}
-		defer file.Close()
+		os.Exit(runFile(interpreter, flag.Arg(0), flag.Args()[1:]))
+	}
+}
 
-		scanner := bufio.NewScanner(file)
-		scanner.Scan()
+// runFile runs the script at path in interpreter and returns the exit status
+// of the process: the one given to os.exit, or 1 if the script could not be
+// parsed or stopped with an error.
+func runFile(interpreter *evaluator.Interpreter, path string, args []string) int {
+	file, err := os.Open(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error: "+err.Error())
+		return 1
+	}
+	defer file.Close()
 
-		l := lexer.New(scanner.Text(), scanner)
-		p := parser.New(l)
+	scanner := bufio.NewScanner(file)
+	scanner.Scan()
 
-		program := p.ParseProgram()
-		// fmt.Println(program.String())
-		if len(p.Errors()) != 0 {
-			for _, err := range p.Errors() {
-				fmt.Println("Parsing error: " + err)
-			}
-		}
+	l := lexer.New(scanner.Text(), scanner)
+	p := parser.New(l)
 
-		env := object.NewEnvironment()
-		evaluated := evaluator.Eval(program, env)
-		if evaluated.Type() == object.ERROR_OBJ {
-			fmt.Println(evaluated.Inspect())
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		for _, err := range p.Errors() {
+			fmt.Fprintln(os.Stderr, "Parsing error: "+err)
 		}
+		return 1
+	}
+
+	interpreter.Args = args
+	interpreter.Modules.SetMainScript(path)
 
+	env := object.NewEnvironment()
+	env.SetInterpreter(interpreter)
+	evaluated := evaluator.Eval(program, env)
+
+	if err, ok := evaluated.(*object.Error); ok {
+		if err.Exit {
+			return err.ExitCode
+		}
+		fmt.Fprintln(os.Stderr, err.Inspect())
+		return 1
 	}
+
+	return 0
 }
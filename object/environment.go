@@ -8,12 +8,16 @@ func NewExtendedEnvironment(outer *Environment) *Environment {
 
 func NewEnvironment() *Environment {
 	s := make(map[string]Object)
-	return &Environment{store: s}
+	c := make(map[string]bool)
+	return &Environment{store: s, constants: c}
 }
 
 type Environment struct {
-	store map[string]Object
-	outer *Environment
+	store       map[string]Object
+	constants   map[string]bool
+	exports     map[string]bool
+	interpreter Interpreter
+	outer       *Environment
 }
 
 func (e *Environment) Get(name string) Object {
@@ -26,7 +30,80 @@ func (e *Environment) Get(name string) Object {
 	return nil
 }
 
+func (e *Environment) GetLocal(name string) Object {
+	return e.store[name]
+}
+
+// Set declares name in this scope, shadowing any binding with the same name
+// in the outer scopes.
 func (e *Environment) Set(name string, value Object) Object {
 	e.store[name] = value
+	delete(e.constants, name)
+	return value
+}
+
+func (e *Environment) SetConstant(name string, value Object) Object {
+	e.store[name] = value
+	e.constants[name] = true
 	return value
 }
+
+// IsConstant reports whether name was bound as a constant in the scope that
+// defines it.
+func (e *Environment) IsConstant(name string) bool {
+	if _, ok := e.store[name]; ok {
+		return e.constants[name]
+	} else if e.outer != nil {
+		return e.outer.IsConstant(name)
+	}
+	return false
+}
+
+// Assign updates name in the closest scope that declares it. It returns nil if
+// the name isn't declared anywhere.
+func (e *Environment) Assign(name string, value Object) Object {
+	if _, ok := e.store[name]; ok {
+		e.store[name] = value
+		return value
+	} else if e.outer != nil {
+		return e.outer.Assign(name, value)
+	}
+	return nil
+}
+
+func (e *Environment) IsTopLevel() bool {
+	return e.outer == nil
+}
+
+func (e *Environment) Export(name string) {
+	if e.exports == nil {
+		e.exports = make(map[string]bool)
+	}
+	e.exports[name] = true
+}
+
+// IsExported reports whether name can be used from other modules. A module
+// without any export statement exposes all of its top level bindings.
+func (e *Environment) IsExported(name string) bool {
+	if _, ok := e.store[name]; !ok {
+		return false
+	}
+	return e.exports == nil || e.exports[name]
+}
+
+// SetInterpreter makes this environment, and the scopes it encloses, run in
+// interp, which holds the module cache and the settings of the interpreter.
+func (e *Environment) SetInterpreter(interp Interpreter) {
+	e.interpreter = interp
+}
+
+// Interpreter returns the interpreter set on this environment or on the
+// closest enclosing one, or nil if there is none.
+func (e *Environment) Interpreter() Interpreter {
+	if e.interpreter != nil {
+		return e.interpreter
+	} else if e.outer != nil {
+		return e.outer.Interpreter()
+	}
+	return nil
+}
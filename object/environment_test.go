@@ -0,0 +1,33 @@
+package object
+
+import "testing"
+
+func TestAssign(t *testing.T) {
+	outer := NewEnvironment()
+	outer.Set("x", &Integer{Value: 1})
+
+	inner := NewExtendedEnvironment(outer)
+
+	if result := inner.Assign("x", &Integer{Value: 2}); result == nil {
+		t.Fatalf("Assign returned nil for a variable of the outer scope")
+	}
+
+	if inner.GetLocal("x") != nil {
+		t.Errorf("Assign declared x in the inner scope")
+	}
+
+	if value := outer.Get("x").(*Integer).Value; value != 2 {
+		t.Errorf("expected the outer x to be 2, got %d", value)
+	}
+
+	inner.Set("x", &Integer{Value: 3})
+	inner.Assign("x", &Integer{Value: 4})
+
+	if value := outer.Get("x").(*Integer).Value; value != 2 {
+		t.Errorf("assigning the shadowing x changed the outer one to %d", value)
+	}
+
+	if result := inner.Assign("y", &Integer{Value: 5}); result != nil {
+		t.Errorf("Assign of an undeclared variable returned %s", result.Inspect())
+	}
+}
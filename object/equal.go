@@ -0,0 +1,94 @@
+package object
+
+import "bytes"
+
+// Equal reports whether a and b hold the same value. Numbers are compared by
+// value whatever their type, arrays and hashes element by element, and
+// functions, builtins and modules by identity.
+func Equal(a, b Object) bool {
+	return equal(a, b, map[[2]Object]bool{})
+}
+
+// equal keeps track of the pairs of arrays and hashes being compared, so that
+// structures containing themselves don't recurse forever.
+func equal(a, b Object, comparing map[[2]Object]bool) bool {
+	if a == b {
+		return true
+	}
+
+	switch a := a.(type) {
+	case *Integer:
+		switch b := b.(type) {
+		case *Integer:
+			return a.Value == b.Value
+		case *Float:
+			return float64(a.Value) == b.Value
+		}
+	case *Float:
+		switch b := b.(type) {
+		case *Integer:
+			return a.Value == float64(b.Value)
+		case *Float:
+			return a.Value == b.Value
+		}
+	case *String:
+		if b, ok := b.(*String); ok {
+			return a.Value == b.Value
+		}
+	case *Bytes:
+		if b, ok := b.(*Bytes); ok {
+			return bytes.Equal(a.Value, b.Value)
+		}
+	case *Boolean:
+		if b, ok := b.(*Boolean); ok {
+			return a.Value == b.Value
+		}
+	case *Null:
+		return b.Type() == NULL_OBJ
+	case *Time:
+		if b, ok := b.(*Time); ok {
+			return a.Value.Equal(b.Value)
+		}
+	case *Array:
+		b, ok := b.(*Array)
+		if !ok || len(a.Elements) != len(b.Elements) {
+			return false
+		}
+
+		pair := [2]Object{a, b}
+		if comparing[pair] {
+			return true
+		}
+		comparing[pair] = true
+		defer delete(comparing, pair)
+
+		for i := range a.Elements {
+			if !equal(a.Elements[i], b.Elements[i], comparing) {
+				return false
+			}
+		}
+		return true
+	case *Hash:
+		b, ok := b.(*Hash)
+		if !ok || len(a.Pairs) != len(b.Pairs) {
+			return false
+		}
+
+		pair := [2]Object{a, b}
+		if comparing[pair] {
+			return true
+		}
+		comparing[pair] = true
+		defer delete(comparing, pair)
+
+		for key, aPair := range a.Pairs {
+			bPair, ok := b.Pairs[key]
+			if !ok || !equal(aPair.Key, bPair.Key, comparing) || !equal(aPair.Value, bPair.Value, comparing) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return false
+}
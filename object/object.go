@@ -3,8 +3,14 @@ package object
 import (
 	"bytes"
 	"fmt"
+	"hash/fnv"
+	"math"
 	"monkey/ast"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type Object interface {
@@ -14,16 +20,34 @@ type Object interface {
 
 type BuiltinFunction func(args ...Object) Object
 
+// Interpreter is the interpreter running a builtin. Call calls a function or
+// a builtin with the given arguments.
+type Interpreter interface {
+	Call(fn Object, args ...Object) Object
+}
+
+// InterpreterFunction is the calling convention of builtins that need the
+// interpreter running them, either to call back into the functions they are
+// given, such as map or sort, or to reach its settings.
+type InterpreterFunction func(interp Interpreter, args ...Object) Object
+
 const (
 	INTEGER_OBJ      = "INTEGER"
+	FLOAT_OBJ        = "FLOAT"
 	BOOLEAN_OBJ      = "BOOLEAN"
 	STRING_OBJ       = "STRING"
+	BYTES_OBJ        = "BYTES"
 	ARRAY_OBJ        = "ARRAY"
+	HASH_OBJ         = "HASH"
 	NULL_OBJ         = "NULL"
 	RETURN_VALUE_OBJ = "RETURN_VALUE"
+	TAIL_CALL_OBJ    = "TAIL_CALL"
 	ERROR_OBJ        = "ERROR"
 	FUNCTION_OBJ     = "FUNCTION"
 	BUILTIN_OBJ      = "BUILTIN"
+	MODULE_OBJ       = "MODULE"
+	TIME_OBJ         = "TIME"
+	REGEX_OBJ        = "REGEX"
 )
 
 type Integer struct {
@@ -37,6 +61,21 @@ func (i *Integer) Type() string {
 	return INTEGER_OBJ
 }
 
+type Float struct {
+	Value float64
+}
+
+func (f *Float) Inspect() string {
+	result := strconv.FormatFloat(f.Value, 'g', -1, 64)
+	if !strings.ContainsAny(result, ".eIN") {
+		result += ".0"
+	}
+	return result
+}
+func (f *Float) Type() string {
+	return FLOAT_OBJ
+}
+
 type Boolean struct {
 	Value bool
 }
@@ -59,27 +98,169 @@ func (s *String) Type() string {
 	return STRING_OBJ
 }
 
+// Bytes holds raw bytes, as opposed to String whose length, indexes and
+// slices count characters.
+type Bytes struct {
+	Value []byte
+}
+
+func (b *Bytes) Inspect() string {
+	return "b" + strconv.Quote(string(b.Value))
+}
+func (b *Bytes) Type() string {
+	return BYTES_OBJ
+}
+
 type Array struct {
 	Elements []Object
+	Frozen   bool
 }
 
 func (a *Array) Inspect() string {
+	return inspect(a, map[Object]bool{})
+}
+
+func (a *Array) Type() string {
+	return ARRAY_OBJ
+}
+
+// inspect renders arrays and hashes, writing [...] or {...} in place of the
+// ones that contain themselves instead of recursing forever.
+func inspect(obj Object, visiting map[Object]bool) string {
 	var result bytes.Buffer
 
-	var elements []string
-	for _, a := range a.Elements {
-		elements = append(elements, a.Inspect())
-	}
+	switch obj := obj.(type) {
+	case *Array:
+		if visiting[obj] {
+			return "[...]"
+		}
+		visiting[obj] = true
+		defer delete(visiting, obj)
 
-	result.WriteString("[")
-	result.WriteString(strings.Join(elements, ", "))
-	result.WriteString("]")
+		var elements []string
+		for _, element := range obj.Elements {
+			elements = append(elements, inspect(element, visiting))
+		}
+
+		result.WriteString("[")
+		result.WriteString(strings.Join(elements, ", "))
+		result.WriteString("]")
+	case *Hash:
+		if visiting[obj] {
+			return "{...}"
+		}
+		visiting[obj] = true
+		defer delete(visiting, obj)
+
+		pairs := []string{}
+		for _, pair := range obj.SortedPairs() {
+			pairs = append(pairs, pair.Key.Inspect()+": "+inspect(pair.Value, visiting))
+		}
+
+		result.WriteString("{")
+		result.WriteString(strings.Join(pairs, ", "))
+		result.WriteString("}")
+	default:
+		return obj.Inspect()
+	}
 
 	return result.String()
 }
 
-func (a *Array) Type() string {
-	return ARRAY_OBJ
+type HashKey struct {
+	Type  string
+	Value uint64
+}
+
+// Hashable is implemented by the objects that can be used as hash keys.
+type Hashable interface {
+	HashKey() HashKey
+}
+
+func (i *Integer) HashKey() HashKey {
+	return HashKey{Type: i.Type(), Value: uint64(i.Value)}
+}
+
+func (b *Boolean) HashKey() HashKey {
+	var value uint64
+	if b.Value {
+		value = 1
+	}
+	return HashKey{Type: b.Type(), Value: value}
+}
+
+// HashKey of a float without a fractional part is the one of the matching
+// integer, since both are equal.
+func (f *Float) HashKey() HashKey {
+	if f.Value == math.Trunc(f.Value) && math.Abs(f.Value) < math.MaxInt64 {
+		return HashKey{Type: INTEGER_OBJ, Value: uint64(int64(f.Value))}
+	}
+	return HashKey{Type: f.Type(), Value: math.Float64bits(f.Value)}
+}
+
+func (s *String) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(s.Value))
+	return HashKey{Type: s.Type(), Value: h.Sum64()}
+}
+
+type HashPair struct {
+	Key   Object
+	Value Object
+}
+
+type Hash struct {
+	Pairs  map[HashKey]HashPair
+	Frozen bool
+}
+
+func NewHash() *Hash {
+	return &Hash{Pairs: make(map[HashKey]HashPair)}
+}
+
+// Get returns the value stored under key. Keys that aren't Hashable are
+// never found.
+func (h *Hash) Get(key Object) (Object, bool) {
+	hashable, ok := key.(Hashable)
+	if !ok {
+		return nil, false
+	}
+
+	pair, ok := h.Pairs[hashable.HashKey()]
+	if !ok || !Equal(pair.Key, key) {
+		return nil, false
+	}
+
+	return pair.Value, true
+}
+
+// SortedPairs returns the pairs ordered by key, so hashes are always printed
+// the same way.
+func (h *Hash) SortedPairs() []HashPair {
+	pairs := make([]HashPair, 0, len(h.Pairs))
+	for _, pair := range h.Pairs {
+		pairs = append(pairs, pair)
+	}
+
+	sort.Slice(pairs, func(i, j int) bool {
+		a, b := pairs[i].Key, pairs[j].Key
+		if a.Type() != b.Type() {
+			return a.Type() < b.Type()
+		}
+		if a, ok := a.(*Integer); ok {
+			return a.Value < b.(*Integer).Value
+		}
+		return a.Inspect() < b.Inspect()
+	})
+
+	return pairs
+}
+
+func (h *Hash) Inspect() string {
+	return inspect(h, map[Object]bool{})
+}
+func (h *Hash) Type() string {
+	return HASH_OBJ
 }
 
 type Null struct{}
@@ -102,8 +283,27 @@ func (ro *ReturnValue) Inspect() string {
 	return ro.Value.Inspect()
 }
 
+// TailCall is returned by `return f(...)` instead of calling f right away, so
+// the caller can run it in a loop without growing the Go stack.
+type TailCall struct {
+	Function  Object
+	Arguments []Object
+	Named     map[string]Object
+}
+
+func (tc *TailCall) Type() string {
+	return TAIL_CALL_OBJ
+}
+func (tc *TailCall) Inspect() string {
+	return "tail call to " + tc.Function.Inspect()
+}
+
 type Error struct {
 	Message string
+	// Exit is set when the script asked to stop with os.exit, in which case
+	// ExitCode is the status it asked for.
+	Exit     bool
+	ExitCode int
 }
 
 func (e *Error) Type() string {
@@ -114,7 +314,10 @@ func (e *Error) Inspect() string {
 }
 
 type Function struct {
+	Name       string
 	Parameters []ast.Identifier
+	Defaults   map[string]ast.Expression
+	Rest       *ast.Identifier
 	Body       ast.BlockStatement
 	Env        Environment
 }
@@ -125,10 +328,7 @@ func (f *Function) Type() string {
 func (f *Function) Inspect() string {
 	var result bytes.Buffer
 
-	parameters := []string{}
-	for _, p := range f.Parameters {
-		parameters = append(parameters, p.String())
-	}
+	parameters := ast.ParametersString(f.Parameters, f.Defaults, f.Rest)
 
 	result.WriteString("fn(")
 	result.WriteString(strings.Join(parameters, ", "))
@@ -139,8 +339,11 @@ func (f *Function) Inspect() string {
 	return result.String()
 }
 
+// Builtin is implemented either by Fn or, when it needs the interpreter, by
+// InterpreterFn.
 type Builtin struct {
-	Fn BuiltinFunction
+	Fn            BuiltinFunction
+	InterpreterFn InterpreterFunction
 }
 
 func (b *Builtin) Type() string {
@@ -149,3 +352,38 @@ func (b *Builtin) Type() string {
 func (b *Builtin) Inspect() string {
 	return "builtin function"
 }
+
+type Module struct {
+	Name string
+	Path string
+	Env  *Environment
+}
+
+func (m *Module) Type() string {
+	return MODULE_OBJ
+}
+func (m *Module) Inspect() string {
+	return "module " + m.Name
+}
+
+type Time struct {
+	Value time.Time
+}
+
+func (t *Time) Type() string {
+	return TIME_OBJ
+}
+func (t *Time) Inspect() string {
+	return t.Value.Format(time.RFC3339Nano)
+}
+
+type Regex struct {
+	Value *regexp.Regexp
+}
+
+func (r *Regex) Type() string {
+	return REGEX_OBJ
+}
+func (r *Regex) Inspect() string {
+	return "regex(" + r.Value.String() + ")"
+}
@@ -2,17 +2,17 @@ package parser
 
 import (
 	"bufio"
-	"fmt"
+	"errors"
 	"monkey/ast"
 	"monkey/lexer"
 	"os"
+	"strings"
 )
 
-func ParseFile(filename string) *ast.Program {
-	file, err := os.Open(fmt.Sprintf("./%s.mk", filename))
+func ParseFile(path string) (*ast.Program, error) {
+	file, err := os.Open(path)
 	if err != nil {
-		fmt.Print("Error: ", err.Error())
-		os.Exit(1)
+		return nil, err
 	}
 	defer file.Close()
 
@@ -24,10 +24,8 @@ func ParseFile(filename string) *ast.Program {
 
 	program := p.ParseProgram()
 	if len(p.Errors()) != 0 {
-		for _, err := range p.Errors() {
-			fmt.Println("Parsing error: " + err)
-		}
+		return nil, errors.New("could not parse " + path + ": " + strings.Join(p.Errors(), "; "))
 	}
 
-	return program
+	return program, nil
 }
@@ -16,6 +16,8 @@ type (
 const (
 	_                  int = iota
 	LOWEST                 // 1
+	PIPELINE               // x |> f
+	COALESCE               // a ?? b
 	EQUALS                 // ==
 	LESSGREATER            // < or >
 	SUM                    // +
@@ -27,17 +29,21 @@ const (
 )
 
 var precedences = map[string]int{
-	token.EQUAL:        EQUALS,
-	token.NOT_EQUAL:    EQUALS,
-	token.LESS_THAN:    LESSGREATER,
-	token.GREATER_THAN: LESSGREATER,
-	token.PLUS:         SUM,
-	token.MINUS:        SUM,
-	token.DIVIDE:       PRODUCT,
-	token.MULTIPLY:     PRODUCT,
-	token.LSQBRACKET:   ARRAY_ACCESS,
-	token.LPAREN:       CALL,
-	token.DOT:          EXTERNAL_REFERENCE,
+	token.PIPE:           PIPELINE,
+	token.COALESCE:       COALESCE,
+	token.EQUAL:          EQUALS,
+	token.NOT_EQUAL:      EQUALS,
+	token.LESS_THAN:      LESSGREATER,
+	token.GREATER_THAN:   LESSGREATER,
+	token.PLUS:           SUM,
+	token.MINUS:          SUM,
+	token.DIVIDE:         PRODUCT,
+	token.MULTIPLY:       PRODUCT,
+	token.LSQBRACKET:     ARRAY_ACCESS,
+	token.OPT_LSQBRACKET: ARRAY_ACCESS,
+	token.LPAREN:         CALL,
+	token.DOT:            EXTERNAL_REFERENCE,
+	token.OPT_DOT:        EXTERNAL_REFERENCE,
 }
 
 type Parser struct {
@@ -57,17 +63,24 @@ func New(l *lexer.Lexer) *Parser {
 	p.infixParserFns = make(map[string]infixParserFn)
 	p.registerPrefix(token.IDENTIFIER, p.parseIdentifier)
 	p.registerPrefix(token.INT, p.parseIntegerLiteral)
+	p.registerPrefix(token.FLOAT, p.parseFloatLiteral)
 	p.registerPrefix(token.STRING, p.parseStringLiteral)
 	p.registerPrefix(token.NOT, p.parsePrefixExpression)
 	p.registerPrefix(token.MINUS, p.parsePrefixExpression)
 	p.registerPrefix(token.TRUE, p.parseBoolean)
 	p.registerPrefix(token.FALSE, p.parseBoolean)
+	p.registerPrefix(token.NULL, p.parseNull)
 	p.registerPrefix(token.LPAREN, p.parseGroupedExpression)
 	p.registerPrefix(token.LSQBRACKET, p.parseArray)
+	p.registerPrefix(token.LBRACE, p.parseHashLiteral)
 	p.registerPrefix(token.IF, p.parseIfExpression)
 	p.registerPrefix(token.FUNCTION, p.parseFunctionLiteral)
+	p.registerPrefix(token.ELLIPSIS, p.parseSpreadExpression)
 
 	p.registerInfix(token.DOT, p.parseExternalReference)
+	p.registerInfix(token.OPT_DOT, p.parseExternalReference)
+	p.registerInfix(token.COALESCE, p.parseInfixExpression)
+	p.registerInfix(token.PIPE, p.parsePipeExpression)
 	p.registerInfix(token.PLUS, p.parseInfixExpression)
 	p.registerInfix(token.MINUS, p.parseInfixExpression)
 	p.registerInfix(token.EQUAL, p.parseInfixExpression)
@@ -78,6 +91,7 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.GREATER_THAN, p.parseInfixExpression)
 	p.registerInfix(token.LPAREN, p.parseCallExpression)
 	p.registerInfix(token.LSQBRACKET, p.parseArrayAccessExpression)
+	p.registerInfix(token.OPT_LSQBRACKET, p.parseArrayAccessExpression)
 
 	p.nextToken()
 	p.nextToken()
@@ -149,7 +163,9 @@ func (p *Parser) parseStatement() ast.Statement {
 	switch p.currentToken.Type {
 	case token.USE:
 		return p.parseUseStatement()
-	case token.LET:
+	case token.EXPORT:
+		return p.parseExportStatement()
+	case token.LET, token.CONST:
 		return p.parseLetStatement()
 	case token.WHILE:
 		return p.parseWhileStatement()
@@ -186,6 +202,47 @@ func (p *Parser) parseUseStatement() ast.Statement {
 
 	statement.Filename = p.currentToken.Literal
 
+	for p.peekToken.Type == token.DIVIDE {
+		p.nextToken()
+
+		if !p.expectToken(token.IDENTIFIER) {
+			return nil
+		}
+
+		statement.Filename += "/" + p.currentToken.Literal
+	}
+
+	if p.peekToken.Type == token.AS {
+		p.nextToken()
+
+		if !p.expectToken(token.IDENTIFIER) {
+			return nil
+		}
+
+		statement.Alias = p.currentToken.Literal
+	} else if p.peekToken.Type == token.LBRACE {
+		p.nextToken()
+
+		statement.Names = []ast.Identifier{}
+
+		for p.peekToken.Type != token.RBRACE {
+			if !p.expectToken(token.IDENTIFIER) {
+				return nil
+			}
+
+			statement.Names = append(statement.Names, ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal})
+
+			if p.peekToken.Type != token.COMMA {
+				break
+			}
+			p.nextToken()
+		}
+
+		if !p.expectToken(token.RBRACE) {
+			return nil
+		}
+	}
+
 	if p.peekToken.Type == token.SEMICOLON {
 		p.nextToken()
 	}
@@ -193,8 +250,39 @@ func (p *Parser) parseUseStatement() ast.Statement {
 	return statement
 }
 
+func (p *Parser) parseExportStatement() ast.Statement {
+	statement := &ast.ExportStatement{Token: p.currentToken}
+
+	p.nextToken()
+
+	switch p.currentToken.Type {
+	case token.LET, token.CONST:
+		statement.Statement = p.parseLetStatement()
+	case token.FUNCTION:
+		if p.peekToken.Type != token.IDENTIFIER {
+			p.AddError(token.IDENTIFIER)
+			return nil
+		}
+		expression := p.parseExpressionStatement()
+		if _, ok := expression.Expression.(*ast.FunctionLiteral); !ok {
+			p.errors = append(p.errors, "export fn must be a plain named function declaration")
+			return nil
+		}
+		statement.Statement = expression
+	default:
+		p.errors = append(p.errors, "only let, const and named fn declarations can be exported, got "+p.currentToken.Type)
+		return nil
+	}
+
+	if statement.Statement == nil {
+		return nil
+	}
+
+	return statement
+}
+
 func (p *Parser) parseLetStatement() ast.Statement {
-	statement := &ast.LetStatement{Token: p.currentToken}
+	statement := &ast.LetStatement{Token: p.currentToken, Constant: p.currentToken.Type == token.CONST}
 
 	if !p.expectToken(token.IDENTIFIER) {
 		return nil
@@ -244,6 +332,10 @@ func (p *Parser) parseWhileStatement() ast.Statement {
 
 	statement.Block = p.parseBlockStatement()
 
+	if p.peekToken.Type == token.SEMICOLON {
+		p.nextToken()
+	}
+
 	return statement
 }
 
@@ -282,6 +374,9 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 	prefix := p.prefixParserFns[p.currentToken.Type]
 
 	if prefix == nil {
+		if p.currentToken.Type == token.ILLEGAL {
+			p.errors = append(p.errors, fmt.Sprintf("Invalid token %q", p.currentToken.Literal))
+		}
 		return nil
 	}
 
@@ -303,20 +398,122 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 }
 
 func (p *Parser) parseGroupedExpression() ast.Expression {
+	if p.peekToken.Type == token.RPAREN {
+		p.nextToken()
+
+		if p.peekToken.Type != token.ARROW {
+			p.AddError(token.ARROW)
+			return nil
+		}
+
+		return p.parseArrowFunction([]ast.Expression{})
+	}
 
 	p.nextToken()
 
-	expression := p.parseExpression(LOWEST)
+	expressions := []ast.Expression{p.parseCallArgument()}
+
+	for p.peekToken.Type == token.COMMA {
+		p.nextToken()
+		p.nextToken()
+		expressions = append(expressions, p.parseCallArgument())
+	}
 
 	if !p.expectToken(token.RPAREN) {
 		return nil
 	}
 
-	return expression
+	if p.peekToken.Type == token.ARROW {
+		return p.parseArrowFunction(expressions)
+	}
+
+	if len(expressions) != 1 {
+		p.AddError(token.ARROW)
+		return nil
+	}
+
+	if _, ok := expressions[0].(*ast.NamedArgument); ok {
+		p.AddError(token.ARROW)
+		return nil
+	}
+
+	return expressions[0]
 }
 
 func (p *Parser) parseIdentifier() ast.Expression {
-	return &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
+	identifier := &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
+
+	if p.peekToken.Type == token.ARROW {
+		return p.parseArrowFunction([]ast.Expression{identifier})
+	}
+
+	return identifier
+}
+
+// parseArrowFunction turns `(a, b = 1, ...rest) => body` into a regular
+// function literal. The parameters were already parsed as expressions, since
+// they can't be told apart from a grouped expression until the arrow shows up.
+func (p *Parser) parseArrowFunction(params []ast.Expression) ast.Expression {
+	fl := &ast.FunctionLiteral{Token: token.Token{Type: token.FUNCTION, Literal: "fn"}}
+	fl.Parameters = []ast.Identifier{}
+	fl.Defaults = make(map[string]ast.Expression)
+
+	for i, param := range params {
+		switch param := param.(type) {
+		case *ast.Identifier:
+			if len(fl.Defaults) > 0 {
+				p.errors = append(p.errors, fmt.Sprintf("parameter %s without a default value follows a parameter with one", param.Value))
+				return nil
+			}
+			if p.isDuplicateParameter(fl, param.Value) {
+				return nil
+			}
+			fl.Parameters = append(fl.Parameters, *param)
+		case *ast.NamedArgument:
+			if p.isDuplicateParameter(fl, param.Name.Value) {
+				return nil
+			}
+			fl.Parameters = append(fl.Parameters, param.Name)
+			fl.Defaults[param.Name.Value] = param.Value
+		case *ast.SpreadExpression:
+			rest, ok := param.Value.(*ast.Identifier)
+			if !ok || i != len(params)-1 {
+				p.errors = append(p.errors, "the rest parameter must be the last parameter")
+				return nil
+			}
+			if p.isDuplicateParameter(fl, rest.Value) {
+				return nil
+			}
+			fl.Rest = rest
+		default:
+			p.errors = append(p.errors, "invalid parameter in arrow function: "+nodeString(param))
+			return nil
+		}
+	}
+
+	p.nextToken()
+
+	if p.peekToken.Type == token.LBRACE {
+		p.nextToken()
+		fl.Block = p.parseBlockStatement()
+		return fl
+	}
+
+	p.nextToken()
+
+	body := &ast.ExpressionStatement{Token: p.currentToken}
+	body.Expression = p.parseExpression(LOWEST)
+
+	fl.Block = ast.BlockStatement{Token: body.Token, Statements: []ast.Statement{body}}
+
+	return fl
+}
+
+func nodeString(node ast.Node) string {
+	if node == nil {
+		return "nothing"
+	}
+	return node.String()
 }
 
 func (p *Parser) parseIntegerLiteral() ast.Expression {
@@ -336,6 +533,23 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 	return il
 }
 
+func (p *Parser) parseFloatLiteral() ast.Expression {
+
+	fl := &ast.FloatLiteral{Token: p.currentToken}
+
+	value, err := strconv.ParseFloat(p.currentToken.Literal, 64)
+	if err != nil {
+		msg := fmt.Sprintf("Could not parse %s to a float", p.currentToken.Literal)
+		p.errors = append(p.errors, msg)
+
+		return nil
+	}
+
+	fl.Value = value
+
+	return fl
+}
+
 func (p *Parser) parseStringLiteral() ast.Expression {
 	sl := &ast.StringLiteral{Token: p.currentToken, Value: p.currentToken.Literal}
 
@@ -370,6 +584,37 @@ func (p *Parser) parseArray() ast.Expression {
 	return arr
 }
 
+func (p *Parser) parseHashLiteral() ast.Expression {
+	hash := &ast.HashLiteral{Token: p.currentToken}
+	hash.Keys = []ast.Expression{}
+	hash.Values = []ast.Expression{}
+
+	for p.peekToken.Type != token.RBRACE {
+		p.nextToken()
+
+		key := p.parseExpression(LOWEST)
+
+		if !p.expectToken(token.COLON) {
+			return nil
+		}
+
+		p.nextToken()
+
+		hash.Keys = append(hash.Keys, key)
+		hash.Values = append(hash.Values, p.parseExpression(LOWEST))
+
+		if p.peekToken.Type != token.RBRACE && !p.expectToken(token.COMMA) {
+			return nil
+		}
+	}
+
+	if !p.expectToken(token.RBRACE) {
+		return nil
+	}
+
+	return hash
+}
+
 func (p *Parser) parsePrefixExpression() ast.Expression {
 	expression := &ast.PrefixExpression{Token: p.currentToken, Operator: p.currentToken.Literal}
 
@@ -401,6 +646,10 @@ func (p *Parser) parseBoolean() ast.Expression {
 	return expression
 }
 
+func (p *Parser) parseNull() ast.Expression {
+	return &ast.Null{Token: p.currentToken}
+}
+
 func (p *Parser) parseIfExpression() ast.Expression {
 	expression := &ast.IfExpression{Token: p.currentToken}
 
@@ -468,7 +717,9 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 
 	p.nextToken()
 
-	fl.Parameters = p.parseFunctionParameters()
+	if !p.parseFunctionParameters(fl) {
+		return nil
+	}
 
 	if !p.expectToken(token.LBRACE) {
 		return nil
@@ -479,27 +730,72 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 	return fl
 }
 
-func (p *Parser) parseFunctionParameters() []ast.Identifier {
-	identifiers := []ast.Identifier{}
+func (p *Parser) parseFunctionParameters(fl *ast.FunctionLiteral) bool {
+	fl.Defaults = make(map[string]ast.Expression)
 
 	if p.currentToken.Type == token.RPAREN {
-		p.nextToken()
-		return identifiers
+		return true
 	}
 
-	identifiers = append(identifiers, ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal})
+	for {
+		if p.currentToken.Type == token.ELLIPSIS {
+			if !p.expectToken(token.IDENTIFIER) {
+				return false
+			}
+			if p.isDuplicateParameter(fl, p.currentToken.Literal) {
+				return false
+			}
+			fl.Rest = &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
+
+			if p.peekToken.Type != token.RPAREN {
+				p.errors = append(p.errors, "the rest parameter must be the last parameter")
+				return false
+			}
+			break
+		}
+
+		if p.currentToken.Type != token.IDENTIFIER {
+			p.errors = append(p.errors, fmt.Sprintf("Expected a parameter name, but got %s instead", p.currentToken.Type))
+			return false
+		}
+
+		param := ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
+		if p.isDuplicateParameter(fl, param.Value) {
+			return false
+		}
+
+		if p.peekToken.Type == token.ASSIGN {
+			p.nextToken()
+			p.nextToken()
+			fl.Defaults[param.Value] = p.parseExpression(LOWEST)
+		} else if len(fl.Defaults) > 0 {
+			p.errors = append(p.errors, fmt.Sprintf("parameter %s without a default value follows a parameter with one", param.Value))
+			return false
+		}
+
+		fl.Parameters = append(fl.Parameters, param)
+
+		if p.peekToken.Type != token.COMMA {
+			break
+		}
 
-	for p.peekToken.Type == token.COMMA {
 		p.nextToken()
 		p.nextToken()
-		identifiers = append(identifiers, ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal})
 	}
 
-	if !p.expectToken(token.RPAREN) {
-		return nil
-	}
+	return p.expectToken(token.RPAREN)
+}
 
-	return identifiers
+// isDuplicateParameter reports an error if name is already one of the
+// parameters of fl.
+func (p *Parser) isDuplicateParameter(fl *ast.FunctionLiteral, name string) bool {
+	for _, param := range fl.Parameters {
+		if param.Value == name {
+			p.errors = append(p.errors, "duplicate parameter "+name)
+			return true
+		}
+	}
+	return false
 }
 
 func (p *Parser) parseCallExpression(left ast.Expression) ast.Expression {
@@ -521,12 +817,12 @@ func (p *Parser) parseCallArguments() []ast.Expression {
 
 	p.nextToken()
 
-	arguments = append(arguments, p.parseExpression(LOWEST))
+	arguments = append(arguments, p.parseCallArgument())
 
 	for p.peekToken.Type == token.COMMA {
 		p.nextToken()
 		p.nextToken()
-		arguments = append(arguments, p.parseExpression(LOWEST))
+		arguments = append(arguments, p.parseCallArgument())
 	}
 
 	if !p.expectToken(token.RPAREN) {
@@ -536,13 +832,52 @@ func (p *Parser) parseCallArguments() []ast.Expression {
 	return arguments
 }
 
+func (p *Parser) parseCallArgument() ast.Expression {
+	if p.currentToken.Type == token.IDENTIFIER && p.peekToken.Type == token.ASSIGN {
+		argument := &ast.NamedArgument{Token: p.currentToken}
+		argument.Name = ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
+
+		p.nextToken()
+		p.nextToken()
+
+		argument.Value = p.parseExpression(LOWEST)
+
+		return argument
+	}
+
+	return p.parseExpression(LOWEST)
+}
+
+func (p *Parser) parseSpreadExpression() ast.Expression {
+	expression := &ast.SpreadExpression{Token: p.currentToken}
+
+	p.nextToken()
+
+	expression.Value = p.parseExpression(PREFIX)
+
+	return expression
+}
+
 func (p *Parser) parseArrayAccessExpression(left ast.Expression) ast.Expression {
-	arrAccess := &ast.ArrayAccessExpression{Token: p.currentToken, Array: left}
+	arrAccess := &ast.ArrayAccessExpression{
+		Token:    p.currentToken,
+		Array:    left,
+		Optional: p.currentToken.Type == token.OPT_LSQBRACKET,
+	}
 
 	p.nextToken()
 
+	if p.currentToken.Type == token.COLON {
+		return p.parseSliceExpression(arrAccess, nil)
+	}
+
 	arrAccess.Position = p.parseExpression(LOWEST)
 
+	if p.peekToken.Type == token.COLON {
+		p.nextToken()
+		return p.parseSliceExpression(arrAccess, arrAccess.Position)
+	}
+
 	if !p.expectToken(token.RSQBRACKET) {
 		return nil
 	}
@@ -550,23 +885,60 @@ func (p *Parser) parseArrayAccessExpression(left ast.Expression) ast.Expression
 	return arrAccess
 }
 
-func (p *Parser) parseExternalReference(left ast.Expression) ast.Expression {
-	expression := &ast.ExternalReferenceExpression{Token: p.currentToken}
+// parseSliceExpression parses the rest of `x[start:end]` once the colon is
+// the current token. Both start and end may be left out.
+func (p *Parser) parseSliceExpression(arrAccess *ast.ArrayAccessExpression, start ast.Expression) ast.Expression {
+	slice := &ast.SliceExpression{
+		Token:    arrAccess.Token,
+		Left:     arrAccess.Array,
+		Start:    start,
+		Optional: arrAccess.Optional,
+	}
 
-	expression.Module = left.String()
+	if p.peekToken.Type != token.RSQBRACKET {
+		p.nextToken()
+		slice.End = p.parseExpression(LOWEST)
+	}
 
-	if p.peekToken.Type != token.IDENTIFIER {
-		p.AddError(token.IDENTIFIER)
+	if !p.expectToken(token.RSQBRACKET) {
 		return nil
 	}
 
+	return slice
+}
+
+// parsePipeExpression rewrites `x |> f` into `f(x)` and `x |> f(y)` into
+// `f(x, y)`, so the evaluator only ever sees regular calls.
+func (p *Parser) parsePipeExpression(left ast.Expression) ast.Expression {
+	pipeToken := p.currentToken
+
 	p.nextToken()
 
-	expression.Referece = p.parseExpression(LOWEST)
+	right := p.parseExpression(PIPELINE)
 
-	if p.peekToken.Type == token.SEMICOLON {
-		p.nextToken()
+	if call, ok := right.(*ast.CallExpression); ok {
+		call.Arguments = append([]ast.Expression{left}, call.Arguments...)
+		return call
+	}
+
+	return &ast.CallExpression{Token: pipeToken, Function: right, Arguments: []ast.Expression{left}}
+}
+
+func (p *Parser) parseExternalReference(left ast.Expression) ast.Expression {
+	expression := &ast.ExternalReferenceExpression{
+		Token:    p.currentToken,
+		Left:     left,
+		Optional: p.currentToken.Type == token.OPT_DOT,
+	}
+
+	if p.peekToken.Type != token.IDENTIFIER {
+		p.AddError(token.IDENTIFIER)
+		return nil
 	}
 
+	p.nextToken()
+
+	expression.Referece = &ast.Identifier{Token: p.currentToken, Value: p.currentToken.Literal}
+
 	return expression
 }
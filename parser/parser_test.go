@@ -0,0 +1,156 @@
+package parser
+
+import (
+	"bufio"
+	"monkey/ast"
+	"monkey/lexer"
+	"strings"
+	"testing"
+)
+
+func parseErrors(input string) []string {
+	l := lexer.New(input, bufio.NewScanner(strings.NewReader("")))
+	p := New(l)
+	p.ParseProgram()
+	return p.Errors()
+}
+
+func TestDuplicateParameters(t *testing.T) {
+	tests := []string{
+		`fn(a, a) { a }`,
+		`fn(a, b = 1, a = 2) { a }`,
+		`fn(a, ...a) { a }`,
+		`(a, a) => a`,
+		`(a, b = 1, a = 2) => a`,
+		`(a, ...a) => a`,
+	}
+
+	for _, input := range tests {
+		errors := parseErrors(input)
+		if len(errors) == 0 || errors[0] != "duplicate parameter a" {
+			t.Errorf("expected %q to fail with a duplicate parameter, got %v", input, errors)
+		}
+	}
+
+	if errors := parseErrors(`fn(a, b) { a }; (a, b) => a`); len(errors) > 0 {
+		t.Errorf("unexpected errors: %v", errors)
+	}
+}
+
+func parseExpression(t *testing.T, input string) ast.Expression {
+	t.Helper()
+
+	l := lexer.New(input, bufio.NewScanner(strings.NewReader("")))
+	p := New(l)
+	program := p.ParseProgram()
+
+	if len(p.Errors()) > 0 {
+		t.Fatalf("parser errors for %q: %v", input, p.Errors())
+	}
+	if len(program.Statements) != 1 {
+		t.Fatalf("expected 1 statement for %q, got %d", input, len(program.Statements))
+	}
+
+	statement, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("expected an expression statement for %q, got %T", input, program.Statements[0])
+	}
+
+	return statement.Expression
+}
+
+func TestArrowFunctions(t *testing.T) {
+	tests := []struct {
+		input    string
+		params   []string
+		defaults []string
+		rest     string
+		body     string
+	}{
+		{`x => x * 2`, []string{"x"}, nil, "", "( x * 2 ) "},
+		{`() => 1`, []string{}, nil, "", "1 "},
+		{`(a, b) => a + b`, []string{"a", "b"}, nil, "", "( a + b ) "},
+		{`(a, b = 1, ...r) => { a + b }`, []string{"a", "b"}, []string{"b"}, "r", "( a + b ) "},
+		{`(...r) => len(r)`, []string{}, nil, "r", "len(r) "},
+	}
+
+	for _, tt := range tests {
+		fl, ok := parseExpression(t, tt.input).(*ast.FunctionLiteral)
+		if !ok {
+			t.Fatalf("expected %q to be a function literal", tt.input)
+		}
+
+		if len(fl.Parameters) != len(tt.params) {
+			t.Fatalf("%q: expected %d parameters, got %d", tt.input, len(tt.params), len(fl.Parameters))
+		}
+		for i, name := range tt.params {
+			if fl.Parameters[i].Value != name {
+				t.Errorf("%q: expected parameter %d to be %s, got %s", tt.input, i, name, fl.Parameters[i].Value)
+			}
+		}
+
+		if len(fl.Defaults) != len(tt.defaults) {
+			t.Errorf("%q: expected %d defaults, got %d", tt.input, len(tt.defaults), len(fl.Defaults))
+		}
+		for _, name := range tt.defaults {
+			if _, ok := fl.Defaults[name]; !ok {
+				t.Errorf("%q: expected a default value for %s", tt.input, name)
+			}
+		}
+
+		rest := ""
+		if fl.Rest != nil {
+			rest = fl.Rest.Value
+		}
+		if rest != tt.rest {
+			t.Errorf("%q: expected rest parameter %q, got %q", tt.input, tt.rest, rest)
+		}
+
+		if fl.Block.String() != tt.body {
+			t.Errorf("%q: expected body %q, got %q", tt.input, tt.body, fl.Block.String())
+		}
+	}
+}
+
+func TestPipeline(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`x |> f`, "f(x)"},
+		{`x |> f(1)`, "f(x, 1)"},
+		{`x |> f(1) |> g(2, 3)`, "g(f(x, 1), 2, 3)"},
+		{`1 + 2 |> f`, "f(( 1 + 2 ))"},
+	}
+
+	for _, tt := range tests {
+		call, ok := parseExpression(t, tt.input).(*ast.CallExpression)
+		if !ok {
+			t.Fatalf("expected %q to be a call expression", tt.input)
+		}
+
+		if call.String() != tt.expected {
+			t.Errorf("expected %q to become %q, got %q", tt.input, tt.expected, call.String())
+		}
+	}
+}
+
+func TestParameterErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`(1, 2)`, "Expected token of type =>, but got EOF instead"},
+		{`(a, ...r, b) => a`, "the rest parameter must be the last parameter"},
+		{`fn(...r, b) { r }`, "the rest parameter must be the last parameter"},
+		{`(a = 1, b) => a`, "parameter b without a default value follows a parameter with one"},
+		{`(a, 1) => a`, "invalid parameter in arrow function: 1"},
+	}
+
+	for _, tt := range tests {
+		errors := parseErrors(tt.input)
+		if len(errors) == 0 || errors[0] != tt.expected {
+			t.Errorf("expected %q to fail with %q, got %v", tt.input, tt.expected, errors)
+		}
+	}
+}
@@ -10,9 +10,15 @@ import (
 	"monkey/parser"
 )
 
-func Start(in io.Reader, out io.Writer) {
+// Start reads lines from in and evaluates them in interpreter until in ends
+// or a line calls os.exit. read takes its input from the same scanner, so
+// lines typed or piped for it aren't swallowed by the REPL.
+func Start(in io.Reader, out io.Writer, interpreter *evaluator.Interpreter) {
 	scanner := bufio.NewScanner(in)
+	interpreter.Stdin = scanner
+
 	env := object.NewEnvironment()
+	env.SetInterpreter(interpreter)
 
 	for {
 		fmt.Print(">> ")
@@ -33,6 +39,9 @@ func Start(in io.Reader, out io.Writer) {
 		fmt.Println(program.String())
 
 		evaluated := evaluator.Eval(program, env)
+		if err, ok := evaluated.(*object.Error); ok && err.Exit {
+			return
+		}
 		if evaluated != nil {
 			fmt.Println(evaluated.Inspect())
 		}
@@ -11,6 +11,7 @@ const (
 
 	IDENTIFIER = "IDENTIFIER"
 	INT        = "INT"
+	FLOAT      = "FLOAT"
 	STRING     = "STRING"
 	ARRAY      = "ARRAY"
 
@@ -20,6 +21,9 @@ const (
 	NOT_EQUAL    = "!="
 	LESS_THAN    = "<"
 	GREATER_THAN = ">"
+	ARROW        = "=>"
+	PIPE         = "|>"
+	COALESCE     = "??"
 
 	PLUS     = "+"
 	MINUS    = "-"
@@ -27,7 +31,10 @@ const (
 	MULTIPLY = "*"
 
 	DOT       = "."
+	OPT_DOT   = "?."
+	ELLIPSIS  = "..."
 	COMMA     = ","
+	COLON     = ":"
 	SEMICOLON = ";"
 
 	LPAREN     = "("
@@ -37,12 +44,18 @@ const (
 	LSQBRACKET = "["
 	RSQBRACKET = "]"
 
+	OPT_LSQBRACKET = "?["
+
 	FUNCTION = "FUNCTION"
 	USE      = "USE"
+	AS       = "AS"
+	EXPORT   = "EXPORT"
 	LET      = "LET"
+	CONST    = "CONST"
 	WHILE    = "WHILE"
 	TRUE     = "TRUE"
 	FALSE    = "FALSE"
+	NULL     = "NULL"
 	IF       = "IF"
 	ELSE     = "ELSE"
 	RETURN   = "RETURN"
@@ -51,10 +64,14 @@ const (
 var keywords = map[string]string{
 	"fn":     FUNCTION,
 	"use":    USE,
+	"as":     AS,
+	"export": EXPORT,
 	"let":    LET,
+	"const":  CONST,
 	"while":  WHILE,
 	"true":   TRUE,
 	"false":  FALSE,
+	"null":   NULL,
 	"if":     IF,
 	"else":   ELSE,
 	"return": RETURN,